@@ -0,0 +1,6 @@
+/*
+analysis measures the quality of spatial pooler configurations, such as
+how evenly columns are used and how well input similarity and noise
+tolerance are preserved in the output SDRs.
+*/
+package analysis
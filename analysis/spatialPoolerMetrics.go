@@ -0,0 +1,325 @@
+package analysis
+
+import (
+	"github.com/nupic-community/htm"
+	"math"
+	"math/rand"
+)
+
+/*
+	Params for spatial pooler analysis
+
+Repetitions -- Number of times the inputs are presented to measure the
+stability of the output SDRs, must be at least 2.
+
+Learn -- When set the pooler learns while the inputs are presented for the
+stability measure. The other metrics are always computed with learning off.
+
+NoiseLevels -- Fractions of active input bits moved to random inactive bits
+for the noise robustness curve.
+
+Seed -- Seed of the random noise.
+*/
+type AnalysisParams struct {
+	Repetitions int
+	Learn       bool
+	NoiseLevels []float64
+	Seed        int
+}
+
+//Create default analysis params
+func NewAnalysisParams() *AnalysisParams {
+	p := new(AnalysisParams)
+
+	p.Repetitions = 5
+	p.Learn = false
+	p.NoiseLevels = []float64{0, 0.05, 0.1, 0.2, 0.3, 0.4, 0.5}
+	p.Seed = 42
+
+	return p
+}
+
+//Validates params, returns a *htm.ParamError naming the first invalid field
+func (p *AnalysisParams) Validate() error {
+	if p.Repetitions < 2 {
+		return &htm.ParamError{Field: "Repetitions", Reason: "must be at least 2"}
+	}
+	for _, noise := range p.NoiseLevels {
+		if noise < 0 || noise > 1 {
+			return &htm.ParamError{Field: "NoiseLevels", Reason: "must be between 0 and 1"}
+		}
+	}
+	return nil
+}
+
+//Mean overlap between the outputs of clean and noisy inputs at a noise level
+type NoisePoint struct {
+	Noise   float64
+	Overlap float64
+}
+
+/*
+	Quality metrics of a spatial pooler for a set of inputs
+
+Entropy -- Normalized entropy of the column usage, see Entropy.
+
+DeadColumnFraction -- Fraction of columns with an active duty cycle of 0.
+
+Stability -- Mean overlap between the outputs of successive presentations of
+the same input, 1 if the outputs never change.
+
+OverlapPreservation -- Correlation between the overlap of input pairs and
+the overlap of their outputs.
+
+NoiseRobustness -- Mean output overlap for each noise level.
+*/
+type Report struct {
+	Entropy             float64
+	DeadColumnFraction  float64
+	Stability           float64
+	OverlapPreservation float64
+	NoiseRobustness     []NoisePoint
+}
+
+/*
+	Computes all metrics of a spatial pooler for the specified inputs.
+Stability is measured first, so with Learn set the remaining metrics
+describe the trained pooler. Without Learn the pooler is left unchanged.
+Panics on invalid params.
+*/
+func Analyze(sp *htm.SpatialPooler, inputs [][]bool, params *AnalysisParams) *Report {
+	if err := params.Validate(); err != nil {
+		panic(err)
+	}
+
+	report := new(Report)
+	report.Stability = Stability(sp, inputs, params.Repetitions, params.Learn)
+	report.DeadColumnFraction = DeadColumnFraction(sp)
+	report.Entropy = Entropy(ColumnUsage(sp, inputs))
+	report.OverlapPreservation = OverlapPreservation(sp, inputs)
+	rng := rand.New(rand.NewSource(int64(params.Seed)))
+	report.NoiseRobustness = NoiseRobustness(sp, inputs, params.NoiseLevels, rng)
+
+	return report
+}
+
+//Computes the output of an input with learning off, without advancing the
+//iteration counter of the pooler
+func infer(sp *htm.SpatialPooler, input *htm.SDR) *htm.SDR {
+	iterationNum := sp.IterationNum
+	output := sp.ComputeSDR(input, false)
+	sp.IterationNum = iterationNum
+	return output
+}
+
+//Returns the output SDR of every input, computed with learning off
+func computeAll(sp *htm.SpatialPooler, inputs []*htm.SDR) []*htm.SDR {
+	result := make([]*htm.SDR, len(inputs))
+	for idx, input := range inputs {
+		result[idx] = infer(sp, input)
+	}
+	return result
+}
+
+//Converts dense inputs to SDRs
+func toSDRs(inputs [][]bool) []*htm.SDR {
+	result := make([]*htm.SDR, len(inputs))
+	for idx, input := range inputs {
+		result[idx] = htm.NewSDRFromDense(input)
+	}
+	return result
+}
+
+/*
+	Returns the overlap of two SDRs divided by the number of active bits
+of the larger one. 0 if either is empty, an empty output carries no
+information even when it is repeated.
+*/
+func Similarity(a, b *htm.SDR) float64 {
+	if a.NumActive() == 0 || b.NumActive() == 0 {
+		return 0
+	}
+	size := a.NumActive()
+	if b.NumActive() > size {
+		size = b.NumActive()
+	}
+	return float64(a.Overlap(b)) / float64(size)
+}
+
+//Returns the fraction of inputs each column is active for, computed
+//with learning off
+func ColumnUsage(sp *htm.SpatialPooler, inputs [][]bool) []float64 {
+	usage := make([]float64, sp.NumColumns())
+	if len(inputs) == 0 {
+		return usage
+	}
+
+	for _, output := range computeAll(sp, toSDRs(inputs)) {
+		for _, col := range output.Sparse() {
+			usage[col]++
+		}
+	}
+	for idx := range usage {
+		usage[idx] /= float64(len(inputs))
+	}
+
+	return usage
+}
+
+//Returns the entropy in bits of a column that is active with probability p
+func binaryEntropy(p float64) float64 {
+	if p <= 0 || p >= 1 {
+		return 0
+	}
+	return -p*math.Log2(p) - (1-p)*math.Log2(1-p)
+}
+
+/*
+	Returns the summed binary entropy of the column activation
+probabilities, divided by its maximum for the same mean activation which
+is reached when every column is used equally. Returns 1 for perfectly even
+usage and 0 when columns are either always or never active.
+*/
+func Entropy(usage []float64) float64 {
+	if len(usage) == 0 {
+		return 0
+	}
+
+	mean := 0.0
+	entropy := 0.0
+	for _, p := range usage {
+		mean += p
+		entropy += binaryEntropy(p)
+	}
+	mean /= float64(len(usage))
+
+	maxEntropy := float64(len(usage)) * binaryEntropy(mean)
+	if maxEntropy == 0 {
+		return 0
+	}
+	return entropy / maxEntropy
+}
+
+//Returns the fraction of columns whose active duty cycle is 0
+func DeadColumnFraction(sp *htm.SpatialPooler) float64 {
+	dutyCycles := sp.GetActiveDutyCycles()
+	if len(dutyCycles) == 0 {
+		return 0
+	}
+
+	dead := 0
+	for _, dutyCycle := range dutyCycles {
+		if dutyCycle == 0 {
+			dead++
+		}
+	}
+	return float64(dead) / float64(len(dutyCycles))
+}
+
+/*
+	Presents the inputs repetitions times and returns the mean similarity
+between the output of each input and its output in the previous
+presentation. Without learning the pooler is left unchanged and the result
+is 1 unless outputs are empty, with learning it shows how far the pooler
+is from converging.
+*/
+func Stability(sp *htm.SpatialPooler, inputs [][]bool, repetitions int, learn bool) float64 {
+	if len(inputs) == 0 || repetitions < 2 {
+		return 1
+	}
+
+	sdrs := toSDRs(inputs)
+	var previous []*htm.SDR
+	total := 0.0
+	for rep := 0; rep < repetitions; rep++ {
+		outputs := make([]*htm.SDR, len(sdrs))
+		for idx, input := range sdrs {
+			if learn {
+				outputs[idx] = sp.ComputeSDR(input, true)
+			} else {
+				outputs[idx] = infer(sp, input)
+			}
+		}
+		if previous != nil {
+			for idx, output := range outputs {
+				total += Similarity(previous[idx], output)
+			}
+		}
+		previous = outputs
+	}
+
+	return total / float64((repetitions-1)*len(inputs))
+}
+
+/*
+	Returns the pearson correlation between the similarity of every pair
+of inputs and the similarity of their outputs. 1 if similar inputs map to
+proportionally similar outputs, 0 if the correlation is undefined because
+all pairs are equally similar.
+*/
+func OverlapPreservation(sp *htm.SpatialPooler, inputs [][]bool) float64 {
+	sdrs := toSDRs(inputs)
+	outputs := computeAll(sp, sdrs)
+
+	var inputSims, outputSims []float64
+	for i := 0; i < len(sdrs); i++ {
+		for j := i + 1; j < len(sdrs); j++ {
+			inputSims = append(inputSims, Similarity(sdrs[i], sdrs[j]))
+			outputSims = append(outputSims, Similarity(outputs[i], outputs[j]))
+		}
+	}
+
+	return correlation(inputSims, outputSims)
+}
+
+//Returns the pearson correlation of x and y, 0 if either is constant
+func correlation(x, y []float64) float64 {
+	if len(x) == 0 {
+		return 0
+	}
+
+	meanX, meanY := 0.0, 0.0
+	for i := range x {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= float64(len(x))
+	meanY /= float64(len(y))
+
+	cov, varX, varY := 0.0, 0.0, 0.0
+	for i := range x {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varX*varY)
+}
+
+/*
+	For each noise level moves that fraction of the active bits of every
+input to random inactive bits, and returns the mean similarity between the
+outputs of the clean and noisy inputs
+*/
+func NoiseRobustness(sp *htm.SpatialPooler, inputs [][]bool, noiseLevels []float64, rng *rand.Rand) []NoisePoint {
+	sdrs := toSDRs(inputs)
+	clean := computeAll(sp, sdrs)
+
+	result := make([]NoisePoint, len(noiseLevels))
+	for i, noise := range noiseLevels {
+		result[i].Noise = noise
+		if len(sdrs) == 0 {
+			continue
+		}
+		for idx, input := range sdrs {
+			noisy := infer(sp, input.AddNoise(noise, rng))
+			result[i].Overlap += Similarity(clean[idx], noisy)
+		}
+		result[i].Overlap /= float64(len(sdrs))
+	}
+
+	return result
+}
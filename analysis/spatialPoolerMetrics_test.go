@@ -0,0 +1,133 @@
+package analysis
+
+import (
+	"github.com/nupic-community/htm"
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+func testSpatialPooler() *htm.SpatialPooler {
+	spParams := htm.NewSpParams()
+	spParams.InputDimensions = []int{100}
+	spParams.ColumnDimensions = []int{200}
+	spParams.PotentialRadius = 100
+	spParams.GlobalInhibition = true
+	spParams.NumActiveColumnsPerInhArea = 10
+	spParams.Seed = 42
+	return htm.NewSpatialPooler(spParams)
+}
+
+//Returns noisy variants of a few random prototypes
+func testInputs(rng *rand.Rand) [][]bool {
+	var inputs [][]bool
+	for p := 0; p < 4; p++ {
+		prototype := htm.NewSDRFromSparse(rng.Perm(100)[:20], 100)
+		for _, noise := range []float64{0, 0.1, 0.2, 0.4} {
+			inputs = append(inputs, prototype.AddNoise(noise, rng).Dense())
+		}
+	}
+	return inputs
+}
+
+func TestEntropy(t *testing.T) {
+	assert.InDelta(t, 1.0, Entropy([]float64{0.1, 0.1, 0.1, 0.1}), 1e-9)
+	assert.Equal(t, 0.0, Entropy([]float64{1, 0, 0, 0}))
+	assert.Equal(t, 0.0, Entropy([]float64{0, 0, 0}))
+	assert.Equal(t, 0.0, Entropy(nil))
+
+	uneven := Entropy([]float64{0.3, 0.05, 0.05})
+	assert.True(t, uneven > 0 && uneven < 1)
+}
+
+func TestSimilarity(t *testing.T) {
+	a := htm.NewSDRFromSparse([]int{1, 2, 3, 4}, 10)
+	b := htm.NewSDRFromSparse([]int{3, 4}, 10)
+	assert.Equal(t, 0.5, Similarity(a, b))
+	assert.Equal(t, 0.5, Similarity(b, a))
+	assert.Equal(t, 0.0, Similarity(htm.NewSDR(10), htm.NewSDR(10)))
+	assert.Equal(t, 0.0, Similarity(a, htm.NewSDR(10)))
+}
+
+func TestCorrelation(t *testing.T) {
+	assert.InDelta(t, 1.0, correlation([]float64{1, 2, 3}, []float64{2, 4, 6}), 1e-9)
+	assert.InDelta(t, -1.0, correlation([]float64{1, 2, 3}, []float64{3, 2, 1}), 1e-9)
+	assert.Equal(t, 0.0, correlation([]float64{1, 1, 1}, []float64{1, 2, 3}))
+}
+
+func TestDeadColumnFraction(t *testing.T) {
+	sp := testSpatialPooler()
+	assert.Equal(t, 1.0, DeadColumnFraction(sp))
+
+	dutyCycles := make([]float64, sp.NumColumns())
+	for i := 0; i < 50; i++ {
+		dutyCycles[i] = 0.1
+	}
+	sp.SetActiveDutyCycles(dutyCycles)
+	assert.Equal(t, 0.75, DeadColumnFraction(sp))
+}
+
+func TestAnalyze(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	sp := testSpatialPooler()
+	inputs := testInputs(rng)
+
+	params := NewAnalysisParams()
+	params.Learn = true
+	params.Repetitions = 10
+	report := Analyze(sp, inputs, params)
+
+	assert.True(t, report.Stability > 0 && report.Stability <= 1)
+	assert.True(t, report.DeadColumnFraction >= 0 && report.DeadColumnFraction < 1)
+	assert.True(t, report.Entropy > 0 && report.Entropy <= 1)
+	//variants of the same prototype map to similar outputs
+	assert.True(t, report.OverlapPreservation > 0.5, "Expected input similarity to be preserved")
+
+	assert.Equal(t, len(params.NoiseLevels), len(report.NoiseRobustness))
+	assert.Equal(t, NoisePoint{0, 1}, report.NoiseRobustness[0])
+	last := report.NoiseRobustness[len(report.NoiseRobustness)-1]
+	assert.Equal(t, 0.5, last.Noise)
+	assert.True(t, last.Overlap < 1, "Expected noise to change the output")
+
+	//without learning the outputs and the pooler never change
+	iterationNum := sp.IterationNum
+	assert.Equal(t, 1.0, Stability(sp, inputs, 3, false))
+	assert.Equal(t, iterationNum, sp.IterationNum)
+	assert.Equal(t, ColumnUsage(sp, inputs), ColumnUsage(sp, inputs))
+}
+
+func TestAnalyzeUntrained(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	sp := testSpatialPooler()
+	inputs := testInputs(rng)
+
+	//an untrained pooler has no output with learning off
+	report := Analyze(sp, inputs, NewAnalysisParams())
+	assert.Equal(t, 0.0, report.Stability)
+	assert.Equal(t, 1.0, report.DeadColumnFraction)
+	assert.Equal(t, 0.0, report.Entropy)
+	assert.Equal(t, 0.0, report.OverlapPreservation)
+	for _, point := range report.NoiseRobustness {
+		assert.Equal(t, 0.0, point.Overlap)
+	}
+
+	//the analysis does not change the pooler
+	assert.Equal(t, 0, sp.IterationNum)
+	assert.Equal(t, 0, sp.IterationLearnNum)
+}
+
+func TestAnalysisParamsValidate(t *testing.T) {
+	p := NewAnalysisParams()
+	assert.Nil(t, p.Validate())
+
+	p.Repetitions = 1
+	err, ok := p.Validate().(*htm.ParamError)
+	assert.True(t, ok)
+	assert.Equal(t, "Repetitions", err.Field)
+
+	p = NewAnalysisParams()
+	p.NoiseLevels = []float64{0.1, 1.5}
+	err, ok = p.Validate().(*htm.ParamError)
+	assert.True(t, ok)
+	assert.Equal(t, "NoiseLevels", err.Field)
+}
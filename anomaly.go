@@ -0,0 +1,90 @@
+package htm
+
+import (
+	"github.com/nupic-community/htm/utils"
+	"sort"
+)
+
+type AnomalyMode int
+
+const (
+	//Fraction of active columns that were not predicted
+	RawAnomaly AnomalyMode = 0
+	//Like RawAnomaly but each predicted column only counts with its
+	//prediction confidence relative to the most confident column
+	ConfidenceWeightedAnomaly AnomalyMode = 1
+)
+
+/*
+	Returns the fraction of active columns that were not in the
+previously predicted columns. Returns 0 if there are no active columns.
+*/
+func ComputeRawAnomalyScore(activeColumns []int, prevPredictedColumns []int) float64 {
+	if len(activeColumns) == 0 {
+		return 0
+	}
+	predicted := 0
+	for _, col := range activeColumns {
+		if utils.ContainsInt(col, prevPredictedColumns) {
+			predicted++
+		}
+	}
+	return 1.0 - float64(predicted)/float64(len(activeColumns))
+}
+
+/*
+	Returns the anomaly score weighted by the confidence of the previous
+predictions. Each correctly predicted active column reduces the score by
+its confidence relative to the most confident predicted column, so if all
+predicted columns had the same confidence the result equals the raw score.
+*/
+func ComputeWeightedAnomalyScore(activeColumns []int, prevPredictedColumns []int,
+	prevColConfidence []float64) float64 {
+	if len(activeColumns) == 0 {
+		return 0
+	}
+
+	maxConfidence := 0.0
+	for _, col := range prevPredictedColumns {
+		if prevColConfidence[col] > maxConfidence {
+			maxConfidence = prevColConfidence[col]
+		}
+	}
+	if maxConfidence <= 0 {
+		return ComputeRawAnomalyScore(activeColumns, prevPredictedColumns)
+	}
+
+	predicted := 0.0
+	for _, col := range activeColumns {
+		if utils.ContainsInt(col, prevPredictedColumns) {
+			predicted += prevColConfidence[col] / maxConfidence
+		}
+	}
+	return 1.0 - predicted/float64(len(activeColumns))
+}
+
+//Returns the active columns that were predicted, or if predicted is
+//false the ones that were not
+func filterPredictedColumns(activeColumns []int, prevPredictedColumns []int, predicted bool) []int {
+	result := make([]int, 0, len(activeColumns))
+	for _, col := range activeColumns {
+		if utils.ContainsInt(col, prevPredictedColumns) == predicted {
+			result = append(result, col)
+		}
+	}
+	return result
+}
+
+//Returns sorted unique column indices
+func uniqueSortedColumns(columns []int) []int {
+	result := make([]int, 0, len(columns))
+	seen := make(map[int]bool, len(columns))
+	for _, col := range columns {
+		if !seen[col] {
+			seen[col] = true
+			result = append(result, col)
+		}
+	}
+	sort.Ints(result)
+	return result
+}
@@ -0,0 +1,247 @@
+package htm
+
+import (
+	"math"
+)
+
+/*
+	Params for the anomaly likelihood estimator
+
+LearningPeriod -- Number of initial records whose scores are ignored when
+estimating the distribution, the model is still learning during them.
+
+EstimationSamples -- Number of records used to estimate the distribution
+after the learning period. No likelihood is reported until
+LearningPeriod + EstimationSamples records have been seen.
+
+HistoricWindowSize -- Maximum number of raw scores kept to estimate the
+distribution from.
+
+ReestimationPeriod -- Number of records between distribution estimates.
+
+AveragingWindow -- Size of the moving average applied to raw scores.
+*/
+type AnomalyLikelihoodParams struct {
+	LearningPeriod     int
+	EstimationSamples  int
+	HistoricWindowSize int
+	ReestimationPeriod int
+	AveragingWindow    int
+}
+
+//Create default anomaly likelihood params
+func NewAnomalyLikelihoodParams() *AnomalyLikelihoodParams {
+	p := new(AnomalyLikelihoodParams)
+
+	p.LearningPeriod = 288
+	p.EstimationSamples = 100
+	p.HistoricWindowSize = 8640
+	p.ReestimationPeriod = 100
+	p.AveragingWindow = 10
+
+	return p
+}
+
+//Validates params, returns a *ParamError naming the first invalid field
+func (p *AnomalyLikelihoodParams) Validate() error {
+	if p.LearningPeriod < 0 {
+		return &ParamError{Field: "LearningPeriod", Reason: "must not be negative"}
+	}
+	if p.EstimationSamples < 1 {
+		return &ParamError{Field: "EstimationSamples", Reason: "must be greater than 0"}
+	}
+	if p.HistoricWindowSize < p.LearningPeriod+p.EstimationSamples {
+		return &ParamError{Field: "HistoricWindowSize", Reason: "must be >= LearningPeriod + EstimationSamples"}
+	}
+	if p.ReestimationPeriod < 1 {
+		return &ParamError{Field: "ReestimationPeriod", Reason: "must be greater than 0"}
+	}
+	if p.AveragingWindow < 1 {
+		return &ParamError{Field: "AveragingWindow", Reason: "must be greater than 0"}
+	}
+	return nil
+}
+
+//Normal distribution fitted to averaged anomaly scores
+type normalDistribution struct {
+	Mean     float64
+	Variance float64
+	Stdev    float64
+}
+
+//Distribution used when there is not enough data to estimate one
+func nullDistribution() normalDistribution {
+	return normalDistribution{Mean: 0.5, Variance: 1e6, Stdev: 1e3}
+}
+
+/*
+	Estimates a normal distribution from values. Mean and variance are
+bounded from below so a model that has learned the data perfectly does not
+turn every small score into an anomaly.
+*/
+func estimateNormal(values []float64) normalDistribution {
+	dist := normalDistribution{}
+	for _, val := range values {
+		dist.Mean += val
+	}
+	dist.Mean /= float64(len(values))
+
+	for _, val := range values {
+		dist.Variance += (val - dist.Mean) * (val - dist.Mean)
+	}
+	dist.Variance /= float64(len(values))
+
+	if dist.Mean < 0.03 {
+		dist.Mean = 0.03
+	}
+	if dist.Variance < 0.0003 {
+		dist.Variance = 0.0003
+	}
+	dist.Stdev = math.Sqrt(dist.Variance)
+
+	return dist
+}
+
+/*
+	Returns the probability of a value at least as far from the mean as x
+on the same side. Values below the mean are reflected onto the upper tail,
+so a score far below the mean is as unlikely as one equally far above it.
+*/
+func (nd normalDistribution) tailProbability(x float64) float64 {
+	if x < nd.Mean {
+		x = 2*nd.Mean - x
+	}
+	z := (x - nd.Mean) / nd.Stdev
+	return 0.5 * math.Erfc(z/math.Sqrt2)
+}
+
+//Moving average over a fixed number of values
+type movingAverage struct {
+	Window []float64
+	Size   int
+	Total  float64
+}
+
+//Adds a value and returns the new average
+func (ma *movingAverage) next(value float64) float64 {
+	ma.Window = append(ma.Window, value)
+	ma.Total += value
+	if len(ma.Window) > ma.Size {
+		ma.Total -= ma.Window[0]
+		ma.Window = ma.Window[1:]
+	}
+	return ma.Total / float64(len(ma.Window))
+}
+
+/*
+ The anomaly likelihood estimator turns noisy raw anomaly scores into the
+likelihood that the current record is anomalous. It keeps a rolling window
+of raw scores, periodically fits a normal distribution to their moving
+average and reports how unlikely the current moving average is under that
+distribution.
+*/
+type AnomalyLikelihood struct {
+	params AnomalyLikelihoodParams
+
+	iteration        int
+	historicalScores []float64
+	//nil until the first estimate
+	distribution  *normalDistribution
+	movingAverage movingAverage
+}
+
+/*
+	Intializes a new anomaly likelihood estimator
+*/
+func NewAnomalyLikelihood(params *AnomalyLikelihoodParams) *AnomalyLikelihood {
+	if err := params.Validate(); err != nil {
+		panic(err)
+	}
+
+	al := new(AnomalyLikelihood)
+	al.params = *params
+	al.historicalScores = make([]float64, 0, params.HistoricWindowSize)
+	al.movingAverage.Size = params.AveragingWindow
+
+	return al
+}
+
+//Number of records before likelihoods are reported
+func (al *AnomalyLikelihood) probationaryPeriod() int {
+	return al.params.LearningPeriod + al.params.EstimationSamples
+}
+
+/*
+	Processes the raw anomaly score of a record, returns the likelihood
+that the record is anomalous and its log likelihood. During the
+probationary period a likelihood of 0.5 is returned.
+*/
+func (al *AnomalyLikelihood) Compute(anomalyScore float64) (likelihood float64, logLikelihood float64) {
+	if len(al.historicalScores) < al.probationaryPeriod() {
+		likelihood = 0.5
+	} else {
+		if al.distribution == nil || al.iteration%al.params.ReestimationPeriod == 0 {
+			al.estimate()
+		}
+		avg := al.movingAverage.next(anomalyScore)
+		likelihood = 1.0 - al.distribution.tailProbability(avg)
+	}
+
+	al.historicalScores = append(al.historicalScores, anomalyScore)
+	if len(al.historicalScores) > al.params.HistoricWindowSize {
+		al.historicalScores = al.historicalScores[1:]
+	}
+	al.iteration++
+
+	return likelihood, ComputeLogLikelihood(likelihood)
+}
+
+/*
+	Number of historical scores to skip when estimating, the scores of
+the learning period are skipped until they are shifted out of the window
+*/
+func (al *AnomalyLikelihood) skipRecords() int {
+	numShiftedOut := al.iteration - al.params.HistoricWindowSize
+	if numShiftedOut < 0 {
+		numShiftedOut = 0
+	}
+	skip := al.params.LearningPeriod - numShiftedOut
+	if skip < 0 {
+		skip = 0
+	}
+	if skip > al.iteration {
+		skip = al.iteration
+	}
+	return skip
+}
+
+/*
+	Fits the distribution to the moving average of the historical scores
+and leaves the moving average positioned after the last historical score
+*/
+func (al *AnomalyLikelihood) estimate() {
+	ma := movingAverage{Size: al.params.AveragingWindow}
+	filtered := make([]float64, len(al.historicalScores))
+	for idx, score := range al.historicalScores {
+		filtered[idx] = ma.next(score)
+	}
+
+	skip := al.skipRecords()
+	var dist normalDistribution
+	if skip >= len(filtered) {
+		dist = nullDistribution()
+	} else {
+		dist = estimateNormal(filtered[skip:])
+	}
+
+	al.distribution = &dist
+	al.movingAverage = ma
+}
+
+/*
+	Returns a log scale version of likelihood that spreads values close
+to 1 apart, 0.5 maps to about 0.03 and 0.9999 to 0.4
+*/
+func ComputeLogLikelihood(likelihood float64) float64 {
+	return math.Log(1.0000000001-likelihood) / -23.02585084720009
+}
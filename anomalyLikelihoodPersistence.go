@@ -0,0 +1,57 @@
+package htm
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+)
+
+//Version of the anomaly likelihood serialization format, bump when
+//anomalyLikelihoodState changes in an incompatible way
+const anomalyLikelihoodSerialVersion = 1
+
+//Serializable snapshot of an anomaly likelihood estimator
+type anomalyLikelihoodState struct {
+	SerialVersion    int
+	Params           AnomalyLikelihoodParams
+	Iteration        int
+	HistoricalScores []float64
+	Distribution     *normalDistribution
+	MovingAverage    movingAverage
+}
+
+//Writes the state of the estimator to w. The estimator can be restored
+//with LoadAnomalyLikelihood.
+func (al *AnomalyLikelihood) Save(w io.Writer) error {
+	state := anomalyLikelihoodState{}
+	state.SerialVersion = anomalyLikelihoodSerialVersion
+	state.Params = al.params
+	state.Iteration = al.iteration
+	state.HistoricalScores = al.historicalScores
+	state.Distribution = al.distribution
+	state.MovingAverage = al.movingAverage
+	return gob.NewEncoder(w).Encode(&state)
+}
+
+//Restores an anomaly likelihood estimator previously written with Save
+func LoadAnomalyLikelihood(r io.Reader) (*AnomalyLikelihood, error) {
+	state := anomalyLikelihoodState{}
+	if err := gob.NewDecoder(r).Decode(&state); err != nil {
+		return nil, err
+	}
+	if state.SerialVersion != anomalyLikelihoodSerialVersion {
+		return nil, fmt.Errorf("unsupported anomaly likelihood version %v", state.SerialVersion)
+	}
+	if err := state.Params.Validate(); err != nil {
+		return nil, err
+	}
+
+	al := NewAnomalyLikelihood(&state.Params)
+	al.iteration = state.Iteration
+	al.historicalScores = append(al.historicalScores, state.HistoricalScores...)
+	al.distribution = state.Distribution
+	al.movingAverage = state.MovingAverage
+	al.movingAverage.Size = state.Params.AveragingWindow
+
+	return al, nil
+}
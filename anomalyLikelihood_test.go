@@ -0,0 +1,90 @@
+package htm
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+func newTestAnomalyLikelihood() *AnomalyLikelihood {
+	p := NewAnomalyLikelihoodParams()
+	p.LearningPeriod = 20
+	p.EstimationSamples = 50
+	p.HistoricWindowSize = 200
+	p.ReestimationPeriod = 10
+	return NewAnomalyLikelihood(p)
+}
+
+func TestAnomalyLikelihood(t *testing.T) {
+	al := newTestAnomalyLikelihood()
+	rng := rand.New(rand.NewSource(42))
+
+	// Probationary period
+	for i := 0; i < 70; i++ {
+		likelihood, logLikelihood := al.Compute(rng.Float64() * 0.2)
+		assert.Equal(t, 0.5, likelihood)
+		assert.InDelta(t, 0.0301, logLikelihood, 1e-4)
+	}
+
+	// Normal scores are not anomalous
+	for i := 0; i < 100; i++ {
+		likelihood, _ := al.Compute(rng.Float64() * 0.2)
+		assert.True(t, likelihood < 0.99)
+	}
+
+	// A run of high scores is
+	var likelihood, logLikelihood float64
+	for i := 0; i < 5; i++ {
+		likelihood, logLikelihood = al.Compute(1.0)
+	}
+	assert.True(t, likelihood > 0.999)
+	assert.True(t, logLikelihood > 0.3)
+	assert.Equal(t, 175, len(al.historicalScores))
+
+	// History is bounded by the window size
+	for i := 0; i < 50; i++ {
+		al.Compute(rng.Float64() * 0.2)
+	}
+	assert.Equal(t, 200, len(al.historicalScores))
+}
+
+func TestComputeLogLikelihood(t *testing.T) {
+	assert.InDelta(t, 0.0301, ComputeLogLikelihood(0.5), 1e-4)
+	assert.InDelta(t, 0.4, ComputeLogLikelihood(0.9999), 1e-4)
+	assert.True(t, ComputeLogLikelihood(0.99999) > ComputeLogLikelihood(0.9999))
+}
+
+func TestAnomalyLikelihoodSaveLoad(t *testing.T) {
+	al := newTestAnomalyLikelihood()
+	rng := rand.New(rand.NewSource(42))
+	for i := 0; i < 95; i++ {
+		al.Compute(rng.Float64() * 0.2)
+	}
+
+	var buf bytes.Buffer
+	assert.Nil(t, al.Save(&buf))
+
+	al2, err := LoadAnomalyLikelihood(&buf)
+	assert.Nil(t, err)
+
+	for i := 0; i < 30; i++ {
+		score := rng.Float64()
+		l1, _ := al.Compute(score)
+		l2, _ := al2.Compute(score)
+		assert.Equal(t, l1, l2)
+	}
+
+	_, err = LoadAnomalyLikelihood(bytes.NewBufferString("garbage"))
+	assert.NotNil(t, err)
+}
+
+func TestAnomalyLikelihoodParamsValidate(t *testing.T) {
+	p := NewAnomalyLikelihoodParams()
+	assert.Nil(t, p.Validate())
+
+	p.HistoricWindowSize = 100
+	err, ok := p.Validate().(*ParamError)
+	assert.True(t, ok)
+	assert.Equal(t, "HistoricWindowSize", err.Field)
+}
@@ -0,0 +1,91 @@
+package htm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestComputeRawAnomalyScore(t *testing.T) {
+	assert.Equal(t, 0.0, ComputeRawAnomalyScore([]int{}, []int{1, 2}))
+	assert.Equal(t, 1.0, ComputeRawAnomalyScore([]int{1, 2}, []int{}))
+	assert.Equal(t, 0.0, ComputeRawAnomalyScore([]int{1, 2}, []int{1, 2, 3}))
+	assert.Equal(t, 0.5, ComputeRawAnomalyScore([]int{1, 2, 3, 4}, []int{1, 2, 7}))
+}
+
+func TestComputeWeightedAnomalyScore(t *testing.T) {
+	confidence := []float64{0, 0.4, 0.2, 0.4, 0}
+
+	// Equal confidences give the raw score
+	assert.Equal(t, 0.5, ComputeWeightedAnomalyScore([]int{1, 4}, []int{1, 3}, confidence))
+	// Low confidence predictions count less
+	assert.InDelta(t, 0.75, ComputeWeightedAnomalyScore([]int{2, 4}, []int{1, 2, 3}, confidence), 1e-9)
+	// Nothing predicted
+	assert.Equal(t, 1.0, ComputeWeightedAnomalyScore([]int{2, 4}, []int{}, confidence))
+}
+
+func TestTemporalMemoryAnomalyScore(t *testing.T) {
+	tmp := NewTemporalMemoryParams()
+	tmp.ColumnDimensions = []int{32}
+	tmp.CellsPerColumn = 4
+	tm := NewTemporalMemory(tmp)
+
+	tm.Compute([]int{0, 1, 2, 3}, false)
+	assert.Equal(t, 1.0, tm.AnomalyScore())
+	assert.Equal(t, []int{0, 1, 2, 3}, tm.UnpredictedActiveColumns())
+
+	// cells 32-47 are in columns 8-11
+	tm.PredictiveCells = []int{33, 37, 41, 46, 45}
+	assert.Equal(t, []int{8, 9, 10, 11}, tm.PredictedColumns())
+
+	tm.Compute([]int{21, 20, 9, 8}, false)
+	assert.Equal(t, 0.5, tm.AnomalyScore())
+	assert.Equal(t, []int{8, 9}, tm.PredictedActiveColumns())
+	assert.Equal(t, []int{20, 21}, tm.UnpredictedActiveColumns())
+
+	tm.PredictiveCells = []int{33, 37, 41, 46}
+	tm.Compute([]int{8, 9, 10, 11}, false)
+	assert.Equal(t, 0.0, tm.AnomalyScore())
+	assert.Equal(t, []int{}, tm.UnpredictedActiveColumns())
+}
+
+func TestTemporalPoolerAnomalyScore(t *testing.T) {
+	tps := NewTemporalPoolerParams()
+	tps.Verbosity = 0
+	tps.NumberOfCols = 50
+	tps.CellsPerColumn = 2
+	tps.ActivationThreshold = 8
+	tps.MinThreshold = 10
+	tps.InitialPerm = 0.5
+	tps.ConnectedPerm = 0.5
+	tps.NewSynapseCount = 10
+	tps.PermanenceDec = 0.0
+	tps.PermanenceInc = 0.1
+	tps.GlobalDecay = 0
+	tps.BurnIn = 1
+	tps.PamLength = 10
+	tps.AnomalyMode = ConfidenceWeightedAnomaly
+	tp := NewTemporalPooler(*tps)
+
+	inputs := make([][]bool, 3)
+	inputs[0] = boolRange(0, 9, 50)
+	inputs[1] = boolRange(10, 19, 50)
+	inputs[2] = boolRange(20, 29, 50)
+
+	for i := 0; i < 10; i++ {
+		for p := range inputs {
+			tp.Compute(inputs[p], true, false)
+		}
+		tp.Reset()
+	}
+
+	tp.Compute(inputs[0], false, true)
+	assert.Equal(t, 1.0, tp.AnomalyScore())
+
+	tp.Compute(inputs[1], false, true)
+	assert.Equal(t, 0.0, tp.AnomalyScore())
+	assert.Equal(t, 10, len(tp.PredictedActiveColumns()))
+
+	tp.Compute(boolRange(40, 49, 50), false, true)
+	assert.Equal(t, 1.0, tp.AnomalyScore())
+	assert.Equal(t, 10, len(tp.UnpredictedActiveColumns()))
+}
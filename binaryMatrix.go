@@ -0,0 +1,142 @@
+package htm
+
+import (
+	"fmt"
+)
+
+/*
+ Binary matrix interface implemented by the sparse, dense, bitset, CSR
+and CSC binary matrices. The poolers only depend on this interface, so the
+representation can be picked per model, or replaced by a custom one,
+with the MatrixFactory params.
+*/
+type BinaryMatrix interface {
+	//Returns the number of rows and columns
+	Dims() (height int, width int)
+	Get(row int, col int) bool
+	Set(row int, col int, value bool)
+	//Replaces specified row with values
+	ReplaceRow(row int, values []bool)
+	//Replaces row with true values at specified indices
+	ReplaceRowByIndices(row int, indices []int)
+	GetDenseRow(row int) []bool
+	//Returns a rows "on" indices
+	GetRowIndices(row int) []int
+	SetRowFromDense(row int, denseRow []bool)
+	//Returns the number of true entries of each row that are also true in row
+	RowAndSum(row []bool) []int
+	//Returns row indexes with at least 1 true column
+	NonZeroRows() []int
+	TotalTrueRows() int
+	TotalNonZeroCount() int
+	//Returns all true entries
+	Entries() []SparseEntry
+	//Returns flattened dense representation
+	Flatten() []bool
+	Clear()
+	FillRow(row int, val bool)
+	//Returns a copy using the same representation
+	Copy() BinaryMatrix
+	//Returns a new matrix with the entries of both matrices set
+	Or(other BinaryMatrix) BinaryMatrix
+	ToString() string
+}
+
+//Creates an empty binary matrix of the specified size
+type BinaryMatrixFactory func(height int, width int) BinaryMatrix
+
+var _ BinaryMatrix = (*SparseBinaryMatrix)(nil)
+var _ BinaryMatrix = (*DenseBinaryMatrix)(nil)
+var _ BinaryMatrix = (*BitsetBinaryMatrix)(nil)
+var _ BinaryMatrix = (*CSRBinaryMatrix)(nil)
+var _ BinaryMatrix = (*CSCBinaryMatrix)(nil)
+
+//Creates sparse binary matrices
+func SparseMatrixFactory(height int, width int) BinaryMatrix {
+	return NewSparseBinaryMatrix(height, width)
+}
+
+//Creates dense binary matrices
+func DenseMatrixFactory(height int, width int) BinaryMatrix {
+	return NewDenseBinaryMatrix(height, width)
+}
+
+//Creates bitset binary matrices
+func BitsetMatrixFactory(height int, width int) BinaryMatrix {
+	return NewBitsetBinaryMatrix(height, width)
+}
+
+//Creates compressed sparse row binary matrices
+func CSRMatrixFactory(height int, width int) BinaryMatrix {
+	return NewCSRBinaryMatrix(height, width)
+}
+
+//Creates compressed sparse column binary matrices
+func CSCMatrixFactory(height int, width int) BinaryMatrix {
+	return NewCSCBinaryMatrix(height, width)
+}
+
+/*
+	Returns the name of the representation of m, custom representations are
+named after their type. Used to record the representation in snapshots.
+*/
+func matrixRepresentation(m BinaryMatrix) string {
+	switch m.(type) {
+	case *SparseBinaryMatrix:
+		return "sparse"
+	case *DenseBinaryMatrix:
+		return "dense"
+	case *BitsetBinaryMatrix:
+		return "bitset"
+	case *CSRBinaryMatrix:
+		return "csr"
+	case *CSCBinaryMatrix:
+		return "csc"
+	}
+	return fmt.Sprintf("%T", m)
+}
+
+//Returns the factory of a named built in representation, see
+//matrixRepresentation
+func matrixFactory(name string) (BinaryMatrixFactory, bool) {
+	switch name {
+	case "sparse":
+		return SparseMatrixFactory, true
+	case "dense":
+		return DenseMatrixFactory, true
+	case "bitset":
+		return BitsetMatrixFactory, true
+	case "csr":
+		return CSRMatrixFactory, true
+	case "csc":
+		return CSCMatrixFactory, true
+	}
+	return nil, false
+}
+
+/*
+	Returns newMatrix, or the factory of the named representation when
+newMatrix is nil. Snapshots of custom representations require newMatrix.
+*/
+func snapshotMatrixFactory(name string, newMatrix BinaryMatrixFactory) (BinaryMatrixFactory, error) {
+	if newMatrix != nil {
+		return newMatrix, nil
+	}
+	factory, ok := matrixFactory(name)
+	if !ok {
+		return nil, fmt.Errorf("snapshot uses custom matrix representation %v, a factory must be passed", name)
+	}
+	return factory, nil
+}
+
+/*
+	Ors 2 matrices of any representation, the result has the
+representation of a
+*/
+func orBinaryMatrix(a BinaryMatrix, b BinaryMatrix) BinaryMatrix {
+	result := a.Copy()
+	for _, val := range b.Entries() {
+		result.Set(val.Row, val.Col, true)
+	}
+	return result
+}
@@ -0,0 +1,136 @@
+package htm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+var testMatrixFactories = map[string]BinaryMatrixFactory{
+	"sparse": SparseMatrixFactory,
+	"dense":  DenseMatrixFactory,
+	"bitset": BitsetMatrixFactory,
+	"csr":    CSRMatrixFactory,
+	"csc":    CSCMatrixFactory,
+}
+
+func TestBinaryMatrixImplementations(t *testing.T) {
+	for name, newMatrix := range testMatrixFactories {
+		m := newMatrix(3, 70)
+		height, width := m.Dims()
+		assert.Equal(t, 3, height, name)
+		assert.Equal(t, 70, width, name)
+
+		m.Set(0, 1, true)
+		m.Set(2, 69, true)
+		m.ReplaceRowByIndices(1, []int{5, 66})
+		assert.True(t, m.Get(2, 69), name)
+		assert.Equal(t, 4, m.TotalNonZeroCount(), name)
+		assert.Equal(t, 3, m.TotalTrueRows(), name)
+		assert.Equal(t, []int{5, 66}, m.GetRowIndices(1), name)
+
+		input := make([]bool, 70)
+		input[1] = true
+		input[66] = true
+		assert.Equal(t, []int{1, 1, 0}, m.RowAndSum(input), name)
+
+		c := m.Copy()
+		c.FillRow(0, false)
+		assert.Equal(t, 3, c.TotalNonZeroCount(), name)
+		assert.True(t, m.Get(0, 1), name)
+
+		// Or across representations
+		for otherName, newOther := range testMatrixFactories {
+			other := newOther(3, 70)
+			other.Set(0, 2, true)
+			result := m.Or(other)
+			assert.Equal(t, 5, result.TotalNonZeroCount(), name+" or "+otherName)
+			assert.True(t, result.Get(0, 2), name+" or "+otherName)
+			assert.Equal(t, 4, m.TotalNonZeroCount(), name)
+		}
+
+		m.Clear()
+		assert.Equal(t, 0, m.TotalNonZeroCount(), name)
+	}
+}
+
+func TestSpatialPoolerMatrixFactory(t *testing.T) {
+	spParams := NewSpParams()
+	spParams.InputDimensions = []int{100}
+	spParams.ColumnDimensions = []int{80}
+	spParams.GlobalInhibition = true
+	spParams.Seed = 5
+
+	spParams.MatrixFactory = nil
+	dense := NewSpatialPooler(spParams)
+	_, ok := dense.connectedSynapses.(*DenseBinaryMatrix)
+	assert.True(t, ok)
+
+	spParams.MatrixFactory = BitsetMatrixFactory
+	bitset := NewSpatialPooler(spParams)
+	_, ok = bitset.connectedSynapses.(*BitsetBinaryMatrix)
+	assert.True(t, ok)
+
+	created := 0
+	spParams.MatrixFactory = func(height int, width int) BinaryMatrix {
+		created++
+		return NewSparseBinaryMatrix(height, width)
+	}
+	custom := NewSpatialPooler(spParams)
+	assert.Equal(t, 2, created)
+
+	for i := 0; i < 10; i++ {
+		input := make([]bool, 100)
+		for j := range input {
+			input[j] = rand.Float64() > 0.8
+		}
+		y1, _ := dense.Compute(input, true)
+		y2, _ := bitset.Compute(input, true)
+		y3, _ := custom.Compute(input, true)
+		assert.Equal(t, y1, y2)
+		assert.Equal(t, y1, y3)
+	}
+}
+
+func TestTemporalPoolerMatrixFactory(t *testing.T) {
+	tps := NewTemporalPoolerParams()
+	tps.NumberOfCols = 50
+	tps.CellsPerColumn = 2
+	tps.ActivationThreshold = 8
+	tps.MinThreshold = 10
+	tps.InitialPerm = 0.5
+	tps.ConnectedPerm = 0.5
+	tps.NewSynapseCount = 10
+	tps.PermanenceDec = 0.0
+	tps.PermanenceInc = 0.1
+	tps.GlobalDecay = 0
+	tps.BurnIn = 1
+	tps.PamLength = 10
+
+	inputs := make([][]bool, 5)
+	for i := range inputs {
+		inputs[i] = boolRange(i*10, i*10+9, 50)
+	}
+
+	for name, newMatrix := range testMatrixFactories {
+		tps.MatrixFactory = newMatrix
+		tp := NewTemporalPooler(*tps)
+
+		for i := 0; i < 10; i++ {
+			for p := 0; p < 5; p++ {
+				tp.Compute(inputs[p], true, false)
+			}
+			tp.Reset()
+		}
+
+		// Each input predicts the next one
+		for i := 0; i < 4; i++ {
+			tp.Compute(inputs[i], false, true)
+			predicted := tp.DynamicState.InfPredictedState.NonZeroRows()
+			assert.Equal(t, 10, len(predicted), name)
+			for _, col := range predicted {
+				assert.True(t, inputs[i+1][col], name)
+			}
+		}
+	}
+}
@@ -0,0 +1,368 @@
+package htm
+
+import (
+	"bytes"
+	"math/bits"
+)
+
+const wordSize = 64
+
+/*
+ Bitset binary matrix packs each row into uint64 words. Row and sums
+are computed a word at a time with popcount, which makes it much faster
+and smaller than the sparse and dense matrices for large column counts.
+*/
+type BitsetBinaryMatrix struct {
+	Width   int
+	Height  int
+	rowSize int
+	words   []uint64
+}
+
+//Create new bitset binary matrix of specified size
+func NewBitsetBinaryMatrix(height, width int) *BitsetBinaryMatrix {
+	m := new(BitsetBinaryMatrix)
+	m.Height = height
+	m.Width = width
+	m.rowSize = (width + wordSize - 1) / wordSize
+	m.words = make([]uint64, height*m.rowSize)
+	return m
+}
+
+//Create bitset binary matrix from specified dense matrix
+func NewBitsetBinaryMatrixFromDense(values [][]bool) *BitsetBinaryMatrix {
+	if len(values) < 1 {
+		panic("No values specified.")
+	}
+
+	m := NewBitsetBinaryMatrix(len(values), len(values[0]))
+	for r := 0; r < m.Height; r++ {
+		m.SetRowFromDense(r, values[r])
+	}
+	return m
+}
+
+//Create bitset binary matrix from specified flattened dense matrix
+func NewBitsetBinaryMatrixFromDense1D(values []bool, rows, cols int) *BitsetBinaryMatrix {
+	if len(values) < 1 {
+		panic("No values specified.")
+	}
+	if len(values) != rows*cols {
+		panic("Invalid size")
+	}
+
+	m := NewBitsetBinaryMatrix(rows, cols)
+	for r := 0; r < m.Height; r++ {
+		m.SetRowFromDense(r, values[r*cols:(r*cols)+cols])
+	}
+	return m
+}
+
+// Creates a bitset binary matrix from specified integer array
+// (any values greater than 0 are true)
+func NewBitsetBinaryMatrixFromInts(values [][]int) *BitsetBinaryMatrix {
+	if len(values) < 1 {
+		panic("No values specified.")
+	}
+
+	m := NewBitsetBinaryMatrix(len(values), len(values[0]))
+	for r := 0; r < m.Height; r++ {
+		for c := 0; c < m.Width; c++ {
+			if values[r][c] > 0 {
+				m.Set(r, c, true)
+			}
+		}
+	}
+	return m
+}
+
+//Returns the words of a row
+func (bm *BitsetBinaryMatrix) row(row int) []uint64 {
+	start := row * bm.rowSize
+	return bm.words[start : start+bm.rowSize]
+}
+
+//Packs a dense row into words
+func packRow(values []bool, size int) []uint64 {
+	result := make([]uint64, size)
+	for idx, val := range values {
+		if val {
+			result[idx/wordSize] |= 1 << uint(idx%wordSize)
+		}
+	}
+	return result
+}
+
+//Calls fn with the index of every set bit of words in ascending order
+func eachSetBit(words []uint64, fn func(idx int)) {
+	for w, word := range words {
+		for word != 0 {
+			bit := bits.TrailingZeros64(word)
+			fn(w*wordSize + bit)
+			word &= word - 1
+		}
+	}
+}
+
+//Returns the number of rows and columns
+func (bm *BitsetBinaryMatrix) Dims() (height int, width int) {
+	return bm.Height, bm.Width
+}
+
+//Returns all true/on indices
+func (bm *BitsetBinaryMatrix) Entries() []SparseEntry {
+	result := make([]SparseEntry, 0, bm.TotalNonZeroCount())
+	for r := 0; r < bm.Height; r++ {
+		eachSetBit(bm.row(r), func(c int) {
+			result = append(result, SparseEntry{Row: r, Col: c})
+		})
+	}
+	return result
+}
+
+//Returns flattend dense represenation
+func (bm *BitsetBinaryMatrix) Flatten() []bool {
+	result := make([]bool, bm.Height*bm.Width)
+	for r := 0; r < bm.Height; r++ {
+		eachSetBit(bm.row(r), func(c int) {
+			result[r*bm.Width+c] = true
+		})
+	}
+	return result
+}
+
+//Wraps row and col into the matrix like DenseBinaryMatrix, so out of
+//range indices never touch padding bits or the words of another row
+func (bm *BitsetBinaryMatrix) wrap(row int, col int) (int, int) {
+	row = row % bm.Height
+	if row < 0 {
+		row += bm.Height
+	}
+	col = col % bm.Width
+	if col < 0 {
+		col += bm.Width
+	}
+	return row, col
+}
+
+//Get value at col,row position
+func (bm *BitsetBinaryMatrix) Get(row int, col int) bool {
+	row, col = bm.wrap(row, col)
+	word := bm.words[row*bm.rowSize+col/wordSize]
+	return word&(1<<uint(col%wordSize)) != 0
+}
+
+//Set value at row,col position
+func (bm *BitsetBinaryMatrix) Set(row int, col int, value bool) {
+	row, col = bm.wrap(row, col)
+	idx := row*bm.rowSize + col/wordSize
+	mask := uint64(1) << uint(col%wordSize)
+	if value {
+		bm.words[idx] |= mask
+	} else {
+		bm.words[idx] &^= mask
+	}
+}
+
+//Replaces specified row with values, assumes values is ordered
+//correctly
+func (bm *BitsetBinaryMatrix) ReplaceRow(row int, values []bool) {
+	bm.validateRowCol(row, len(values))
+	copy(bm.row(row), packRow(values[:bm.Width], bm.rowSize))
+}
+
+//Replaces row with true values at specified indices
+func (bm *BitsetBinaryMatrix) ReplaceRowByIndices(row int, indices []int) {
+	bm.validateRow(row)
+	words := bm.row(row)
+	for idx := range words {
+		words[idx] = 0
+	}
+	for _, col := range indices {
+		if col >= 0 && col < bm.Width {
+			bm.Set(row, col, true)
+		}
+	}
+}
+
+//Returns dense row
+func (bm *BitsetBinaryMatrix) GetDenseRow(row int) []bool {
+	bm.validateRow(row)
+	result := make([]bool, bm.Width)
+	eachSetBit(bm.row(row), func(c int) {
+		result[c] = true
+	})
+	return result
+}
+
+//Returns a rows "on" indices
+func (bm *BitsetBinaryMatrix) GetRowIndices(row int) []int {
+	result := []int{}
+	eachSetBit(bm.row(row), func(c int) {
+		result = append(result, c)
+	})
+	return result
+}
+
+//Sets a row from dense representation
+func (bm *BitsetBinaryMatrix) SetRowFromDense(row int, denseRow []bool) {
+	bm.ReplaceRow(row, denseRow)
+}
+
+//In a normal matrix this would be multiplication in binary terms
+//we just and then sum the true entries
+func (bm *BitsetBinaryMatrix) RowAndSum(row []bool) []int {
+	bm.validateCol(len(row))
+	packed := packRow(row[:bm.Width], bm.rowSize)
+	result := make([]int, bm.Height)
+
+	for r := 0; r < bm.Height; r++ {
+		sum := 0
+		for idx, word := range bm.row(r) {
+			sum += bits.OnesCount64(word & packed[idx])
+		}
+		result[r] = sum
+	}
+
+	return result
+}
+
+//Returns # of true values in a row
+func (bm *BitsetBinaryMatrix) RowCount(row int) int {
+	count := 0
+	for _, word := range bm.row(row) {
+		count += bits.OnesCount64(word)
+	}
+	return count
+}
+
+//Returns row indexes with at least 1 true column
+func (bm *BitsetBinaryMatrix) NonZeroRows() []int {
+	var result []int
+	for r := 0; r < bm.Height; r++ {
+		for _, word := range bm.row(r) {
+			if word != 0 {
+				result = append(result, r)
+				break
+			}
+		}
+	}
+	return result
+}
+
+//Returns # of rows with at least 1 true value
+func (bm *BitsetBinaryMatrix) TotalTrueRows() int {
+	return len(bm.NonZeroRows())
+}
+
+//Returns # of cols with at least 1 true value
+func (bm *BitsetBinaryMatrix) TotalTrueCols() int {
+	cols := make([]uint64, bm.rowSize)
+	for r := 0; r < bm.Height; r++ {
+		for idx, word := range bm.row(r) {
+			cols[idx] |= word
+		}
+	}
+	count := 0
+	for _, word := range cols {
+		count += bits.OnesCount64(word)
+	}
+	return count
+}
+
+//Returns total true entries
+func (bm *BitsetBinaryMatrix) TotalNonZeroCount() int {
+	count := 0
+	for _, word := range bm.words {
+		count += bits.OnesCount64(word)
+	}
+	return count
+}
+
+// Ors 2 matrices
+func (bm *BitsetBinaryMatrix) Or(other BinaryMatrix) BinaryMatrix {
+	bm2, ok := other.(*BitsetBinaryMatrix)
+	if !ok {
+		return orBinaryMatrix(bm, other)
+	}
+
+	result := bm.copyBitset()
+	for idx, word := range bm2.words {
+		result.words[idx] |= word
+	}
+	return result
+}
+
+//Clears  all entries
+func (bm *BitsetBinaryMatrix) Clear() {
+	for idx := range bm.words {
+		bm.words[idx] = 0
+	}
+}
+
+//Fills specified row with specified value
+func (bm *BitsetBinaryMatrix) FillRow(row int, val bool) {
+	words := bm.row(row)
+	for idx := range words {
+		words[idx] = 0
+	}
+	if !val {
+		return
+	}
+	for c := 0; c < bm.Width; c++ {
+		bm.Set(row, c, true)
+	}
+}
+
+//Copys a matrix
+func (bm *BitsetBinaryMatrix) Copy() BinaryMatrix {
+	if bm == nil {
+		return nil
+	}
+	return bm.copyBitset()
+}
+
+func (bm *BitsetBinaryMatrix) copyBitset() *BitsetBinaryMatrix {
+	result := new(BitsetBinaryMatrix)
+	result.Width = bm.Width
+	result.Height = bm.Height
+	result.rowSize = bm.rowSize
+	result.words = make([]uint64, len(bm.words))
+	copy(result.words, bm.words)
+
+	return result
+}
+
+func (bm *BitsetBinaryMatrix) ToString() string {
+	var buffer bytes.Buffer
+
+	for r := 0; r < bm.Height; r++ {
+		for c := 0; c < bm.Width; c++ {
+			if bm.Get(r, c) {
+				buffer.WriteByte('1')
+			} else {
+				buffer.WriteByte('0')
+			}
+		}
+		buffer.WriteByte('\n')
+	}
+
+	return buffer.String()
+}
+
+func (bm *BitsetBinaryMatrix) validateCol(col int) {
+	if col > bm.Width {
+		panic("Specified row is wider than matrix.")
+	}
+}
+
+func (bm *BitsetBinaryMatrix) validateRow(row int) {
+	if row > bm.Height {
+		panic("Specified row is out of bounds.")
+	}
+}
+
+func (bm *BitsetBinaryMatrix) validateRowCol(row int, col int) {
+	bm.validateCol(col)
+	bm.validateRow(row)
+}
@@ -0,0 +1,155 @@
+package htm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestBitsetGetSet(t *testing.T) {
+	bm := NewBitsetBinaryMatrix(10, 130)
+	bm.Set(2, 4, true)
+	bm.Set(6, 65, true)
+	bm.Set(7, 129, true)
+	bm.Set(7, 129, false)
+
+	assert.True(t, bm.Get(2, 4))
+	assert.True(t, bm.Get(6, 65))
+	assert.False(t, bm.Get(7, 129))
+	assert.False(t, bm.Get(6, 64))
+	assert.Equal(t, 2, bm.TotalNonZeroCount())
+	assert.Equal(t, []SparseEntry{{Row: 2, Col: 4}, {Row: 6, Col: 65}}, bm.Entries())
+}
+
+func TestBitsetGetSetOutOfRange(t *testing.T) {
+	bm := NewBitsetBinaryMatrix(3, 70)
+	dm := NewDenseBinaryMatrix(3, 70)
+
+	//indices wrap around like the dense matrix
+	for _, m := range []BinaryMatrix{bm, dm} {
+		m.Set(0, 70, true)
+		m.Set(1, 141, true)
+		m.Set(5, 3, true)
+	}
+	assert.Equal(t, dm.Entries(), bm.Entries())
+	assert.Equal(t, []SparseEntry{{Row: 0, Col: 0}, {Row: 1, Col: 1}, {Row: 2, Col: 3}}, bm.Entries())
+	assert.Equal(t, 3, bm.TotalNonZeroCount())
+	assert.True(t, bm.Get(0, 140))
+	assert.True(t, bm.Get(2, 73))
+
+	//negative indices count from the end
+	bm.Set(-1, -1, true)
+	assert.True(t, bm.Get(2, 69))
+	assert.Equal(t, 4, bm.TotalNonZeroCount())
+}
+
+func TestBitsetRowReplace(t *testing.T) {
+	bm := NewBitsetBinaryMatrix(10, 70)
+	bm.Set(8, 8, true)
+	bm.Set(8, 68, true)
+
+	newRow := make([]bool, 70)
+	newRow[6] = true
+	newRow[69] = true
+	bm.ReplaceRow(8, newRow)
+
+	assert.Equal(t, []int{6, 69}, bm.GetRowIndices(8))
+	assert.Equal(t, newRow, bm.GetDenseRow(8))
+
+	bm.ReplaceRowByIndices(8, []int{3, 66, 9})
+	assert.Equal(t, []int{3, 9, 66}, bm.GetRowIndices(8))
+	assert.Equal(t, 3, bm.RowCount(8))
+
+	bm.FillRow(8, true)
+	assert.Equal(t, 70, bm.RowCount(8))
+	bm.FillRow(8, false)
+	assert.Equal(t, 0, bm.TotalNonZeroCount())
+	assert.Equal(t, []int{}, bm.GetRowIndices(8))
+}
+
+func TestBitsetRowAndSum(t *testing.T) {
+	bm := NewBitsetBinaryMatrix(4, 5)
+	bm.SetRowFromDense(0, []bool{true, false, true, true, false})
+	bm.SetRowFromDense(1, []bool{false, false, false, true, false})
+	bm.SetRowFromDense(2, []bool{false, false, false, false, false})
+	bm.SetRowFromDense(3, []bool{true, true, true, true, true})
+
+	result := bm.RowAndSum([]bool{true, false, true, true, false})
+	assert.Equal(t, []int{3, 1, 0, 3}, result)
+	assert.Equal(t, []int{0, 1, 3}, bm.NonZeroRows())
+	assert.Equal(t, 3, bm.TotalTrueRows())
+	assert.Equal(t, 5, bm.TotalTrueCols())
+}
+
+func TestBitsetOrCopy(t *testing.T) {
+	a := NewBitsetBinaryMatrixFromInts([][]int{
+		{1, 0, 0},
+		{0, 0, 1},
+	})
+	b := NewBitsetBinaryMatrixFromDense([][]bool{
+		{false, true, false},
+		{false, false, true},
+	})
+
+	result := a.Or(b)
+	assert.Equal(t, "110\n001\n", result.ToString())
+	assert.Equal(t, "100\n001\n", a.ToString())
+
+	c := result.Copy()
+	c.Clear()
+	assert.Equal(t, 0, c.TotalNonZeroCount())
+	assert.Equal(t, 3, result.TotalNonZeroCount())
+
+	var nilMatrix *BitsetBinaryMatrix
+	assert.Nil(t, nilMatrix.Copy())
+}
+
+func TestBitsetMatchesSparse(t *testing.T) {
+	rows, cols := 20, 150
+	values := make([]bool, rows*cols)
+	for idx := range values {
+		values[idx] = rand.Intn(10) < 3
+	}
+
+	bm := NewBitsetBinaryMatrixFromDense1D(values, rows, cols)
+	sm := NewSparseBinaryMatrixFromDense1D(values, rows, cols)
+
+	assert.Equal(t, values, bm.Flatten())
+	assert.Equal(t, sm.TotalNonZeroCount(), bm.TotalNonZeroCount())
+	assert.Equal(t, sm.TotalTrueCols(), bm.TotalTrueCols())
+
+	input := make([]bool, cols)
+	for idx := range input {
+		input[idx] = rand.Intn(2) == 0
+	}
+	assert.Equal(t, sm.RowAndSum(input), bm.RowAndSum(input))
+
+	expected := sm.NonZeroRows()
+	sort.Ints(expected)
+	assert.Equal(t, expected, bm.NonZeroRows())
+
+	for r := 0; r < rows; r++ {
+		expected := sm.GetRowIndices(r)
+		sort.Ints(expected)
+		assert.Equal(t, expected, bm.GetRowIndices(r))
+	}
+}
+
+func BenchmarkBitsetRowAndSum(b *testing.B) {
+	bm := NewBitsetBinaryMatrix(2048, 1024)
+	for r := 0; r < bm.Height; r++ {
+		for c := 0; c < bm.Width; c++ {
+			bm.Set(r, c, rand.Intn(2) == 0)
+		}
+	}
+	input := make([]bool, bm.Width)
+	for idx := range input {
+		input[idx] = rand.Intn(50) == 0
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bm.RowAndSum(input)
+	}
+}
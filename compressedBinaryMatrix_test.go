@@ -0,0 +1,116 @@
+package htm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+func randomSparseMatrix(rows, cols int) *SparseBinaryMatrix {
+	values := make([]bool, rows*cols)
+	for idx := range values {
+		values[idx] = rand.Intn(10) < 2
+	}
+	return NewSparseBinaryMatrixFromDense1D(values, rows, cols)
+}
+
+func TestCSRBinaryMatrix(t *testing.T) {
+	m := NewCSRBinaryMatrixFromDense([][]bool{
+		{true, false, true, false},
+		{false, false, false, false},
+		{false, true, true, false},
+	})
+
+	assert.Equal(t, []int{0, 2}, m.GetRowIndices(0))
+	assert.Equal(t, []int{0, 2}, m.GetColIndices(2))
+	assert.Equal(t, []int{}, m.GetColIndices(3))
+	assert.Equal(t, []int{2, 0, 2}, m.RowSums())
+	assert.Equal(t, []int{1, 1, 2, 0}, m.ColSums())
+	assert.Equal(t, 3, m.TotalTrueCols())
+	assert.Equal(t, []int{0, 2}, m.NonZeroRows())
+	assert.Equal(t, []int{1, 0, 2}, m.RowAndSum([]bool{false, true, true, false}))
+
+	m.Set(1, 3, true)
+	m.Set(0, 0, false)
+	m.Set(0, 0, false)
+	assert.Equal(t, "0010\n0001\n0110\n", m.ToString())
+
+	m.ReplaceRowByIndices(2, []int{3, 0, 3, 9})
+	assert.Equal(t, []int{0, 3}, m.GetRowIndices(2))
+	m.FillRow(1, true)
+	assert.Equal(t, 4, m.RowSums()[1])
+
+	transposed := m.Transpose()
+	assert.Equal(t, 4, transposed.Height)
+	assert.Equal(t, 3, transposed.Width)
+	assert.Equal(t, "011\n010\n110\n011\n", transposed.ToString())
+}
+
+func TestCSCBinaryMatrix(t *testing.T) {
+	m := NewCSCBinaryMatrixFromDense([][]bool{
+		{true, false, true, false},
+		{false, false, false, false},
+		{false, true, true, false},
+	})
+
+	assert.Equal(t, []int{0, 2}, m.GetRowIndices(0))
+	assert.Equal(t, []int{0, 2}, m.GetColIndices(2))
+	assert.Equal(t, []int{2, 0, 2}, m.RowSums())
+	assert.Equal(t, []int{1, 1, 2, 0}, m.ColSums())
+	assert.Equal(t, 3, m.TotalTrueCols())
+	assert.Equal(t, []int{0, 2}, m.NonZeroRows())
+	assert.Equal(t, []int{1, 0, 2}, m.RowAndSum([]bool{false, true, true, false}))
+
+	m.ReplaceRow(1, []bool{true, false, false, true})
+	assert.Equal(t, []int{0, 1}, m.GetColIndices(0))
+	assert.Equal(t, "1010\n1001\n0110\n", m.ToString())
+
+	m.FillRow(0, false)
+	assert.Equal(t, []int{}, m.GetRowIndices(0))
+	assert.Equal(t, 4, m.TotalNonZeroCount())
+
+	transposed := m.Transpose()
+	assert.Equal(t, "010\n001\n001\n010\n", transposed.ToString())
+	assert.Equal(t, []int{2}, transposed.GetRowIndices(2))
+}
+
+func TestCompressedConversions(t *testing.T) {
+	sm := randomSparseMatrix(30, 70)
+
+	csr := NewCSRBinaryMatrixFromMatrix(sm)
+	csc := NewCSCBinaryMatrixFromMatrix(sm)
+	assert.Equal(t, sm.Flatten(), csr.Flatten())
+	assert.Equal(t, sm.Flatten(), csc.Flatten())
+	assert.Equal(t, csr.Entries(), csc.Entries())
+	assert.Equal(t, csr.Flatten(), csc.ToCSR().Flatten())
+	assert.Equal(t, csc.Flatten(), csr.ToCSC().Flatten())
+	assert.Equal(t, sm.TotalTrueCols(), csr.TotalTrueCols())
+	assert.Equal(t, sm.TotalTrueCols(), csc.TotalTrueCols())
+
+	// transposing twice is the identity
+	assert.Equal(t, csr.Flatten(), csr.Transpose().Transpose().Flatten())
+	transposed := csr.Transpose()
+	for r := 0; r < 30; r++ {
+		for c := 0; c < 70; c++ {
+			assert.Equal(t, sm.Get(r, c), transposed.Get(c, r))
+		}
+	}
+	for c := 0; c < 70; c++ {
+		assert.Equal(t, csr.GetColIndices(c), csc.GetColIndices(c))
+		assert.Equal(t, transposed.GetRowIndices(c), csc.GetColIndices(c))
+	}
+
+	input := make([]bool, 70)
+	for idx := range input {
+		input[idx] = rand.Intn(2) == 0
+	}
+	assert.Equal(t, sm.RowAndSum(input), csr.RowAndSum(input))
+	assert.Equal(t, sm.RowAndSum(input), csc.RowAndSum(input))
+
+	other := randomSparseMatrix(30, 70)
+	expected := sm.Or(other).Flatten()
+	assert.Equal(t, expected, csr.Or(other).Flatten())
+	assert.Equal(t, expected, csc.Or(other).Flatten())
+	assert.Equal(t, expected, csr.Or(NewCSRBinaryMatrixFromMatrix(other)).Flatten())
+	assert.Equal(t, expected, csc.Or(NewCSCBinaryMatrixFromMatrix(other)).Flatten())
+}
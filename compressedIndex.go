@@ -0,0 +1,260 @@
+package htm
+
+import (
+	"sort"
+)
+
+/*
+	Compressed index of the true entries of a binary matrix along its
+major axis, rows for CSR and columns for CSC. The minor indices of major
+i are idx[ptr[i]:ptr[i+1]] in ascending order.
+*/
+type compressedIndex struct {
+	ptr []int
+	idx []int
+}
+
+func newCompressedIndex(majorSize int) compressedIndex {
+	return compressedIndex{ptr: make([]int, majorSize+1)}
+}
+
+/*
+	Builds a compressed index from pairs of major and minor indices,
+duplicate pairs are dropped
+*/
+func newCompressedIndexFromPairs(majorSize int, majors []int, minors []int) compressedIndex {
+	ci := newCompressedIndex(majorSize)
+	for _, major := range majors {
+		ci.ptr[major+1]++
+	}
+	for i := 0; i < majorSize; i++ {
+		ci.ptr[i+1] += ci.ptr[i]
+	}
+
+	ci.idx = make([]int, len(minors))
+	next := make([]int, majorSize)
+	copy(next, ci.ptr[:majorSize])
+	for i, major := range majors {
+		ci.idx[next[major]] = minors[i]
+		next[major]++
+	}
+
+	// sort and drop duplicates within each major
+	write := 0
+	start := 0
+	for major := 0; major < majorSize; major++ {
+		end := ci.ptr[major+1]
+		segment := ci.idx[start:end]
+		sort.Ints(segment)
+		ci.ptr[major] = write
+		for i, minor := range segment {
+			if i > 0 && minor == segment[i-1] {
+				continue
+			}
+			ci.idx[write] = minor
+			write++
+		}
+		start = end
+	}
+	ci.ptr[majorSize] = write
+	ci.idx = ci.idx[:write]
+
+	return ci
+}
+
+func (ci *compressedIndex) majorSize() int {
+	return len(ci.ptr) - 1
+}
+
+//Returns the minor indices of major, the slice must not be modified
+func (ci *compressedIndex) segment(major int) []int {
+	return ci.idx[ci.ptr[major]:ci.ptr[major+1]]
+}
+
+//Returns the position of minor in idx and whether it is present
+func (ci *compressedIndex) find(major int, minor int) (int, bool) {
+	segment := ci.segment(major)
+	pos := sort.SearchInts(segment, minor)
+	return ci.ptr[major] + pos, pos < len(segment) && segment[pos] == minor
+}
+
+func (ci *compressedIndex) get(major int, minor int) bool {
+	_, found := ci.find(major, minor)
+	return found
+}
+
+func (ci *compressedIndex) set(major int, minor int, value bool) {
+	pos, found := ci.find(major, minor)
+	if found == value {
+		return
+	}
+
+	if value {
+		ci.idx = append(ci.idx, 0)
+		copy(ci.idx[pos+1:], ci.idx[pos:])
+		ci.idx[pos] = minor
+		for i := major + 1; i < len(ci.ptr); i++ {
+			ci.ptr[i]++
+		}
+	} else {
+		ci.idx = append(ci.idx[:pos], ci.idx[pos+1:]...)
+		for i := major + 1; i < len(ci.ptr); i++ {
+			ci.ptr[i]--
+		}
+	}
+}
+
+//Replaces the minor indices of major, minors must be sorted and unique
+func (ci *compressedIndex) replace(major int, minors []int) {
+	start, end := ci.ptr[major], ci.ptr[major+1]
+	delta := len(minors) - (end - start)
+
+	result := make([]int, 0, len(ci.idx)+delta)
+	result = append(result, ci.idx[:start]...)
+	result = append(result, minors...)
+	result = append(result, ci.idx[end:]...)
+	ci.idx = result
+
+	for i := major + 1; i < len(ci.ptr); i++ {
+		ci.ptr[i] += delta
+	}
+}
+
+//Returns a copy of the minor indices of major
+func (ci *compressedIndex) indices(major int) []int {
+	segment := ci.segment(major)
+	result := make([]int, len(segment))
+	copy(result, segment)
+	return result
+}
+
+func (ci *compressedIndex) count(major int) int {
+	return ci.ptr[major+1] - ci.ptr[major]
+}
+
+//Returns the majors that contain minor
+func (ci *compressedIndex) majorsOf(minor int) []int {
+	result := []int{}
+	for major := 0; major < ci.majorSize(); major++ {
+		if ci.get(major, minor) {
+			result = append(result, major)
+		}
+	}
+	return result
+}
+
+//Returns the number of true entries of each major
+func (ci *compressedIndex) majorSums() []int {
+	result := make([]int, ci.majorSize())
+	for major := range result {
+		result[major] = ci.count(major)
+	}
+	return result
+}
+
+//Returns the number of true entries of each minor
+func (ci *compressedIndex) minorSums(minorSize int) []int {
+	result := make([]int, minorSize)
+	for _, minor := range ci.idx {
+		result[minor]++
+	}
+	return result
+}
+
+//Returns the majors with at least 1 true entry
+func (ci *compressedIndex) nonZeroMajors() []int {
+	var result []int
+	for major := 0; major < ci.majorSize(); major++ {
+		if ci.count(major) > 0 {
+			result = append(result, major)
+		}
+	}
+	return result
+}
+
+//Returns the compressed index along the other axis
+func (ci *compressedIndex) transpose(minorSize int) compressedIndex {
+	result := newCompressedIndex(minorSize)
+	for _, minor := range ci.idx {
+		result.ptr[minor+1]++
+	}
+	for i := 0; i < minorSize; i++ {
+		result.ptr[i+1] += result.ptr[i]
+	}
+
+	// majors are visited in order so every segment ends up sorted
+	result.idx = make([]int, len(ci.idx))
+	next := make([]int, minorSize)
+	copy(next, result.ptr[:minorSize])
+	for major := 0; major < ci.majorSize(); major++ {
+		for _, minor := range ci.segment(major) {
+			result.idx[next[minor]] = major
+			next[minor]++
+		}
+	}
+
+	return result
+}
+
+//Returns a compressed index with the entries of both indices
+func (ci *compressedIndex) union(other *compressedIndex) compressedIndex {
+	result := newCompressedIndex(ci.majorSize())
+	result.idx = make([]int, 0, len(ci.idx)+len(other.idx))
+	for major := 0; major < ci.majorSize(); major++ {
+		a, b := ci.segment(major), other.segment(major)
+		i, j := 0, 0
+		for i < len(a) || j < len(b) {
+			switch {
+			case j == len(b) || (i < len(a) && a[i] < b[j]):
+				result.idx = append(result.idx, a[i])
+				i++
+			case i == len(a) || b[j] < a[i]:
+				result.idx = append(result.idx, b[j])
+				j++
+			default:
+				result.idx = append(result.idx, a[i])
+				i++
+				j++
+			}
+		}
+		result.ptr[major+1] = len(result.idx)
+	}
+	return result
+}
+
+func (ci *compressedIndex) copy() compressedIndex {
+	result := compressedIndex{}
+	result.ptr = make([]int, len(ci.ptr))
+	copy(result.ptr, ci.ptr)
+	result.idx = make([]int, len(ci.idx))
+	copy(result.idx, ci.idx)
+	return result
+}
+
+func (ci *compressedIndex) clear() {
+	for i := range ci.ptr {
+		ci.ptr[i] = 0
+	}
+	ci.idx = ci.idx[:0]
+}
+
+//Returns sorted unique indices below size
+func normalizeIndices(indices []int, size int) []int {
+	result := make([]int, 0, len(indices))
+	for _, idx := range indices {
+		if idx >= 0 && idx < size {
+			result = append(result, idx)
+		}
+	}
+	sort.Ints(result)
+
+	write := 0
+	for i, idx := range result {
+		if i > 0 && idx == result[i-1] {
+			continue
+		}
+		result[write] = idx
+		write++
+	}
+	return result[:write]
+}
@@ -0,0 +1,264 @@
+package htm
+
+/*
+ Compressed sparse column binary matrix. The row indices of each column
+are stored contiguously in ascending order, so column access and column
+sums are proportional to the number of true entries in the column, and
+RowAndSum only visits the columns that are on in the input. Row updates
+rebuild the index, use a CSRBinaryMatrix for row heavy access.
+*/
+type CSCBinaryMatrix struct {
+	Width  int
+	Height int
+	index  compressedIndex
+}
+
+//Create new CSC binary matrix of specified size
+func NewCSCBinaryMatrix(height, width int) *CSCBinaryMatrix {
+	m := new(CSCBinaryMatrix)
+	m.Height = height
+	m.Width = width
+	m.index = newCompressedIndex(width)
+	return m
+}
+
+//Create CSC binary matrix from specified dense matrix
+func NewCSCBinaryMatrixFromDense(values [][]bool) *CSCBinaryMatrix {
+	if len(values) < 1 {
+		panic("No values specified.")
+	}
+	return NewCSRBinaryMatrixFromDense(values).ToCSC()
+}
+
+//Create CSC binary matrix with the entries of any binary matrix
+func NewCSCBinaryMatrixFromMatrix(bm BinaryMatrix) *CSCBinaryMatrix {
+	m := new(CSCBinaryMatrix)
+	m.Height, m.Width = bm.Dims()
+
+	entries := bm.Entries()
+	rows := make([]int, len(entries))
+	cols := make([]int, len(entries))
+	for idx, entry := range entries {
+		rows[idx] = entry.Row
+		cols[idx] = entry.Col
+	}
+	m.index = newCompressedIndexFromPairs(m.Width, cols, rows)
+	return m
+}
+
+//Returns the number of rows and columns
+func (m *CSCBinaryMatrix) Dims() (height int, width int) {
+	return m.Height, m.Width
+}
+
+//Returns all true/on indices in row major order
+func (m *CSCBinaryMatrix) Entries() []SparseEntry {
+	return m.ToCSR().Entries()
+}
+
+//Returns flattend dense represenation
+func (m *CSCBinaryMatrix) Flatten() []bool {
+	result := make([]bool, m.Height*m.Width)
+	for c := 0; c < m.Width; c++ {
+		for _, r := range m.index.segment(c) {
+			result[r*m.Width+c] = true
+		}
+	}
+	return result
+}
+
+//Get value at col,row position
+func (m *CSCBinaryMatrix) Get(row int, col int) bool {
+	return m.index.get(col, row)
+}
+
+//Set value at row,col position
+func (m *CSCBinaryMatrix) Set(row int, col int, value bool) {
+	m.index.set(col, row, value)
+}
+
+//Replaces a row by editing the row major form of the matrix
+func (m *CSCBinaryMatrix) updateRow(row int, indices []int) {
+	csr := m.ToCSR()
+	csr.index.replace(row, indices)
+	m.index = csr.index.transpose(m.Width)
+}
+
+//Replaces specified row with values, assumes values is ordered
+//correctly
+func (m *CSCBinaryMatrix) ReplaceRow(row int, values []bool) {
+	m.validateRowCol(row, len(values))
+	indices := make([]int, 0, len(values))
+	for c := 0; c < m.Width; c++ {
+		if values[c] {
+			indices = append(indices, c)
+		}
+	}
+	m.updateRow(row, indices)
+}
+
+//Replaces row with true values at specified indices
+func (m *CSCBinaryMatrix) ReplaceRowByIndices(row int, indices []int) {
+	m.validateRow(row)
+	m.updateRow(row, normalizeIndices(indices, m.Width))
+}
+
+//Returns dense row
+func (m *CSCBinaryMatrix) GetDenseRow(row int) []bool {
+	m.validateRow(row)
+	result := make([]bool, m.Width)
+	for _, c := range m.index.majorsOf(row) {
+		result[c] = true
+	}
+	return result
+}
+
+//Returns a rows "on" indices
+func (m *CSCBinaryMatrix) GetRowIndices(row int) []int {
+	return m.index.majorsOf(row)
+}
+
+//Returns the rows that are "on" in a column
+func (m *CSCBinaryMatrix) GetColIndices(col int) []int {
+	return m.index.indices(col)
+}
+
+//Sets a row from dense representation
+func (m *CSCBinaryMatrix) SetRowFromDense(row int, denseRow []bool) {
+	m.ReplaceRow(row, denseRow)
+}
+
+//In a normal matrix this would be multiplication in binary terms
+//we just and then sum the true entries
+func (m *CSCBinaryMatrix) RowAndSum(row []bool) []int {
+	m.validateCol(len(row))
+	result := make([]int, m.Height)
+	for c := 0; c < m.Width; c++ {
+		if !row[c] {
+			continue
+		}
+		for _, r := range m.index.segment(c) {
+			result[r]++
+		}
+	}
+	return result
+}
+
+//Returns the number of true values in each row
+func (m *CSCBinaryMatrix) RowSums() []int {
+	return m.index.minorSums(m.Height)
+}
+
+//Returns the number of true values in each column
+func (m *CSCBinaryMatrix) ColSums() []int {
+	return m.index.majorSums()
+}
+
+//Returns row indexes with at least 1 true column
+func (m *CSCBinaryMatrix) NonZeroRows() []int {
+	var result []int
+	for r, sum := range m.RowSums() {
+		if sum > 0 {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+//Returns # of rows with at least 1 true value
+func (m *CSCBinaryMatrix) TotalTrueRows() int {
+	return len(m.NonZeroRows())
+}
+
+//Returns # of cols with at least 1 true value
+func (m *CSCBinaryMatrix) TotalTrueCols() int {
+	return len(m.index.nonZeroMajors())
+}
+
+//Returns total true entries
+func (m *CSCBinaryMatrix) TotalNonZeroCount() int {
+	return len(m.index.idx)
+}
+
+// Ors 2 matrices
+func (m *CSCBinaryMatrix) Or(other BinaryMatrix) BinaryMatrix {
+	m2, ok := other.(*CSCBinaryMatrix)
+	if !ok {
+		m2 = NewCSCBinaryMatrixFromMatrix(other)
+	}
+
+	result := new(CSCBinaryMatrix)
+	result.Height = m.Height
+	result.Width = m.Width
+	result.index = m.index.union(&m2.index)
+	return result
+}
+
+//Clears  all entries
+func (m *CSCBinaryMatrix) Clear() {
+	m.index.clear()
+}
+
+//Fills specified row with specified value
+func (m *CSCBinaryMatrix) FillRow(row int, val bool) {
+	var indices []int
+	if val {
+		indices = make([]int, m.Width)
+		for c := range indices {
+			indices[c] = c
+		}
+	}
+	m.updateRow(row, indices)
+}
+
+//Copys a matrix
+func (m *CSCBinaryMatrix) Copy() BinaryMatrix {
+	if m == nil {
+		return nil
+	}
+
+	result := new(CSCBinaryMatrix)
+	result.Width = m.Width
+	result.Height = m.Height
+	result.index = m.index.copy()
+	return result
+}
+
+//Returns the transposed matrix
+func (m *CSCBinaryMatrix) Transpose() *CSCBinaryMatrix {
+	result := new(CSCBinaryMatrix)
+	result.Height = m.Width
+	result.Width = m.Height
+	result.index = m.index.transpose(m.Height)
+	return result
+}
+
+//Converts to row major representation
+func (m *CSCBinaryMatrix) ToCSR() *CSRBinaryMatrix {
+	result := new(CSRBinaryMatrix)
+	result.Height = m.Height
+	result.Width = m.Width
+	result.index = m.index.transpose(m.Height)
+	return result
+}
+
+func (m *CSCBinaryMatrix) ToString() string {
+	return m.ToCSR().ToString()
+}
+
+func (m *CSCBinaryMatrix) validateCol(col int) {
+	if col > m.Width {
+		panic("Specified row is wider than matrix.")
+	}
+}
+
+func (m *CSCBinaryMatrix) validateRow(row int) {
+	if row > m.Height {
+		panic("Specified row is out of bounds.")
+	}
+}
+
+func (m *CSCBinaryMatrix) validateRowCol(row int, col int) {
+	m.validateCol(col)
+	m.validateRow(row)
+}
@@ -0,0 +1,288 @@
+package htm
+
+import (
+	"bytes"
+)
+
+/*
+ Compressed sparse row binary matrix. The column indices of each row
+are stored contiguously in ascending order, so row access, row sums and
+RowAndSum are proportional to the number of true entries in the rows
+involved. Column queries need a binary search per row, use a
+CSCBinaryMatrix for column heavy access.
+*/
+type CSRBinaryMatrix struct {
+	Width  int
+	Height int
+	index  compressedIndex
+}
+
+//Create new CSR binary matrix of specified size
+func NewCSRBinaryMatrix(height, width int) *CSRBinaryMatrix {
+	m := new(CSRBinaryMatrix)
+	m.Height = height
+	m.Width = width
+	m.index = newCompressedIndex(height)
+	return m
+}
+
+//Create CSR binary matrix from specified dense matrix
+func NewCSRBinaryMatrixFromDense(values [][]bool) *CSRBinaryMatrix {
+	if len(values) < 1 {
+		panic("No values specified.")
+	}
+
+	m := NewCSRBinaryMatrix(len(values), len(values[0]))
+	for r := 0; r < m.Height; r++ {
+		m.SetRowFromDense(r, values[r])
+	}
+	return m
+}
+
+//Create CSR binary matrix with the entries of any binary matrix
+func NewCSRBinaryMatrixFromMatrix(bm BinaryMatrix) *CSRBinaryMatrix {
+	m := new(CSRBinaryMatrix)
+	m.Height, m.Width = bm.Dims()
+
+	entries := bm.Entries()
+	rows := make([]int, len(entries))
+	cols := make([]int, len(entries))
+	for idx, entry := range entries {
+		rows[idx] = entry.Row
+		cols[idx] = entry.Col
+	}
+	m.index = newCompressedIndexFromPairs(m.Height, rows, cols)
+	return m
+}
+
+//Returns the number of rows and columns
+func (m *CSRBinaryMatrix) Dims() (height int, width int) {
+	return m.Height, m.Width
+}
+
+//Returns all true/on indices in row major order
+func (m *CSRBinaryMatrix) Entries() []SparseEntry {
+	result := make([]SparseEntry, 0, len(m.index.idx))
+	for r := 0; r < m.Height; r++ {
+		for _, c := range m.index.segment(r) {
+			result = append(result, SparseEntry{Row: r, Col: c})
+		}
+	}
+	return result
+}
+
+//Returns flattend dense represenation
+func (m *CSRBinaryMatrix) Flatten() []bool {
+	result := make([]bool, m.Height*m.Width)
+	for r := 0; r < m.Height; r++ {
+		for _, c := range m.index.segment(r) {
+			result[r*m.Width+c] = true
+		}
+	}
+	return result
+}
+
+//Get value at col,row position
+func (m *CSRBinaryMatrix) Get(row int, col int) bool {
+	return m.index.get(row, col)
+}
+
+//Set value at row,col position
+func (m *CSRBinaryMatrix) Set(row int, col int, value bool) {
+	m.index.set(row, col, value)
+}
+
+//Replaces specified row with values, assumes values is ordered
+//correctly
+func (m *CSRBinaryMatrix) ReplaceRow(row int, values []bool) {
+	m.validateRowCol(row, len(values))
+	indices := make([]int, 0, len(values))
+	for c := 0; c < m.Width; c++ {
+		if values[c] {
+			indices = append(indices, c)
+		}
+	}
+	m.index.replace(row, indices)
+}
+
+//Replaces row with true values at specified indices
+func (m *CSRBinaryMatrix) ReplaceRowByIndices(row int, indices []int) {
+	m.validateRow(row)
+	m.index.replace(row, normalizeIndices(indices, m.Width))
+}
+
+//Returns dense row
+func (m *CSRBinaryMatrix) GetDenseRow(row int) []bool {
+	m.validateRow(row)
+	result := make([]bool, m.Width)
+	for _, c := range m.index.segment(row) {
+		result[c] = true
+	}
+	return result
+}
+
+//Returns a rows "on" indices
+func (m *CSRBinaryMatrix) GetRowIndices(row int) []int {
+	return m.index.indices(row)
+}
+
+//Returns the rows that are "on" in a column
+func (m *CSRBinaryMatrix) GetColIndices(col int) []int {
+	return m.index.majorsOf(col)
+}
+
+//Sets a row from dense representation
+func (m *CSRBinaryMatrix) SetRowFromDense(row int, denseRow []bool) {
+	m.ReplaceRow(row, denseRow)
+}
+
+//In a normal matrix this would be multiplication in binary terms
+//we just and then sum the true entries
+func (m *CSRBinaryMatrix) RowAndSum(row []bool) []int {
+	m.validateCol(len(row))
+	result := make([]int, m.Height)
+	for r := 0; r < m.Height; r++ {
+		for _, c := range m.index.segment(r) {
+			if row[c] {
+				result[r]++
+			}
+		}
+	}
+	return result
+}
+
+//Returns the number of true values in each row
+func (m *CSRBinaryMatrix) RowSums() []int {
+	return m.index.majorSums()
+}
+
+//Returns the number of true values in each column
+func (m *CSRBinaryMatrix) ColSums() []int {
+	return m.index.minorSums(m.Width)
+}
+
+//Returns row indexes with at least 1 true column
+func (m *CSRBinaryMatrix) NonZeroRows() []int {
+	return m.index.nonZeroMajors()
+}
+
+//Returns # of rows with at least 1 true value
+func (m *CSRBinaryMatrix) TotalTrueRows() int {
+	return len(m.NonZeroRows())
+}
+
+//Returns # of cols with at least 1 true value
+func (m *CSRBinaryMatrix) TotalTrueCols() int {
+	count := 0
+	for _, sum := range m.ColSums() {
+		if sum > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+//Returns total true entries
+func (m *CSRBinaryMatrix) TotalNonZeroCount() int {
+	return len(m.index.idx)
+}
+
+// Ors 2 matrices
+func (m *CSRBinaryMatrix) Or(other BinaryMatrix) BinaryMatrix {
+	m2, ok := other.(*CSRBinaryMatrix)
+	if !ok {
+		m2 = NewCSRBinaryMatrixFromMatrix(other)
+	}
+
+	result := new(CSRBinaryMatrix)
+	result.Height = m.Height
+	result.Width = m.Width
+	result.index = m.index.union(&m2.index)
+	return result
+}
+
+//Clears  all entries
+func (m *CSRBinaryMatrix) Clear() {
+	m.index.clear()
+}
+
+//Fills specified row with specified value
+func (m *CSRBinaryMatrix) FillRow(row int, val bool) {
+	var indices []int
+	if val {
+		indices = make([]int, m.Width)
+		for c := range indices {
+			indices[c] = c
+		}
+	}
+	m.index.replace(row, indices)
+}
+
+//Copys a matrix
+func (m *CSRBinaryMatrix) Copy() BinaryMatrix {
+	if m == nil {
+		return nil
+	}
+	return m.copyCSR()
+}
+
+func (m *CSRBinaryMatrix) copyCSR() *CSRBinaryMatrix {
+	result := new(CSRBinaryMatrix)
+	result.Width = m.Width
+	result.Height = m.Height
+	result.index = m.index.copy()
+	return result
+}
+
+//Returns the transposed matrix
+func (m *CSRBinaryMatrix) Transpose() *CSRBinaryMatrix {
+	result := new(CSRBinaryMatrix)
+	result.Height = m.Width
+	result.Width = m.Height
+	result.index = m.index.transpose(m.Width)
+	return result
+}
+
+//Converts to column major representation
+func (m *CSRBinaryMatrix) ToCSC() *CSCBinaryMatrix {
+	result := new(CSCBinaryMatrix)
+	result.Height = m.Height
+	result.Width = m.Width
+	result.index = m.index.transpose(m.Width)
+	return result
+}
+
+func (m *CSRBinaryMatrix) ToString() string {
+	var buffer bytes.Buffer
+
+	for r := 0; r < m.Height; r++ {
+		row := m.GetDenseRow(r)
+		for _, val := range row {
+			if val {
+				buffer.WriteByte('1')
+			} else {
+				buffer.WriteByte('0')
+			}
+		}
+		buffer.WriteByte('\n')
+	}
+
+	return buffer.String()
+}
+
+func (m *CSRBinaryMatrix) validateCol(col int) {
+	if col > m.Width {
+		panic("Specified row is wider than matrix.")
+	}
+}
+
+func (m *CSRBinaryMatrix) validateRow(row int) {
+	if row > m.Height {
+		panic("Specified row is out of bounds.")
+	}
+}
+
+func (m *CSRBinaryMatrix) validateRowCol(row int, col int) {
+	m.validateCol(col)
+	m.validateRow(row)
+}
@@ -81,6 +81,11 @@ func (sm *DenseBinaryMatrix) toIndex(index int) (row int, col int) {
 	return
 }
 
+//Returns the number of rows and columns
+func (sm *DenseBinaryMatrix) Dims() (height int, width int) {
+	return sm.Height, sm.Width
+}
+
 //Returns all true/on indices
 func (sm *DenseBinaryMatrix) Entries() []SparseEntry {
 	result := make([]SparseEntry, 0, int(float64(len(sm.entries))*0.3))
@@ -232,7 +237,7 @@ func (sm *DenseBinaryMatrix) TotalNonZeroCount() int {
 }
 
 // Ors 2 matrices
-func (sm *DenseBinaryMatrix) Or(sm2 *DenseBinaryMatrix) *DenseBinaryMatrix {
+func (sm *DenseBinaryMatrix) Or(sm2 BinaryMatrix) BinaryMatrix {
 	result := NewDenseBinaryMatrix(sm.Height, sm.Width)
 
 	for _, val := range sm.Entries() {
@@ -259,7 +264,7 @@ func (sm *DenseBinaryMatrix) FillRow(row int, val bool) {
 }
 
 //Copys a matrix
-func (sm *DenseBinaryMatrix) Copy() *DenseBinaryMatrix {
+func (sm *DenseBinaryMatrix) Copy() BinaryMatrix {
 	if sm == nil {
 		return nil
 	}
@@ -0,0 +1,312 @@
+package encoders
+
+import (
+	"fmt"
+	"github.com/nupic-community/htm"
+	"github.com/nupic-community/htm/utils"
+	"sort"
+)
+
+//Name of the slot used for categories that are not known to the encoder
+const UnknownCategory = "<UNKNOWN>"
+
+/*
+	Params for the category encoder
+
+Width -- Number of bits used to represent each category.
+
+MaxCategories -- Number of category slots reserved in the output, excluding
+the unknown slot. Defaults to the number of initial categories, must be set
+higher to leave room for learned categories.
+
+LearnCategories -- When set, unseen categories encoded with learn enabled are
+assigned the next free slot instead of the unknown slot.
+*/
+type CategoryEncoderParams struct {
+	Width           int
+	Categories      []string
+	MaxCategories   int
+	LearnCategories bool
+	Name            string
+	Verbosity       int
+}
+
+func NewCategoryEncoderParams(width int, categories []string) *CategoryEncoderParams {
+	p := new(CategoryEncoderParams)
+
+	p.Width = width
+	p.Categories = categories
+	p.MaxCategories = len(categories)
+	p.LearnCategories = false
+	p.Name = ""
+	p.Verbosity = 0
+
+	return p
+}
+
+//Validates params, returns a *htm.ParamError naming the first invalid field
+func (p *CategoryEncoderParams) Validate() error {
+	if p.Width < 1 {
+		return &htm.ParamError{Field: "Width", Reason: "must be greater than 0"}
+	}
+	if p.MaxCategories < 1 {
+		return &htm.ParamError{Field: "MaxCategories", Reason: "must be greater than 0"}
+	}
+	if p.MaxCategories < len(p.Categories) {
+		return &htm.ParamError{Field: "MaxCategories", Reason: "must be >= number of categories"}
+	}
+	seen := make(map[string]bool, len(p.Categories))
+	for _, c := range p.Categories {
+		if c == UnknownCategory || seen[c] {
+			return &htm.ParamError{Field: "Categories", Reason: fmt.Sprintf("duplicate or reserved category %v", c)}
+		}
+		seen[c] = true
+	}
+	return nil
+}
+
+/*
+ A category encoder encodes a string value into a block of Width bits.
+Each category gets its own non overlapping block, the first block is
+reserved for unknown categories. The output size is fixed at
+Width * (MaxCategories + 1) so learned categories do not change the
+input size of downstream regions.
+*/
+type CategoryEncoder struct {
+	CategoryEncoderParams
+
+	n               int
+	categoryToIndex map[string]int
+	//slot names, index 0 is the unknown slot
+	indexToCategory []string
+	topDownMappingM *htm.SparseBinaryMatrix
+}
+
+/*
+	Intializes a new category encoder
+*/
+func NewCategoryEncoder(p *CategoryEncoderParams) *CategoryEncoder {
+	if err := p.Validate(); err != nil {
+		panic(err)
+	}
+
+	ce := new(CategoryEncoder)
+	ce.CategoryEncoderParams = *p
+	ce.n = ce.Width * (ce.MaxCategories + 1)
+
+	ce.categoryToIndex = make(map[string]int, ce.MaxCategories)
+	ce.indexToCategory = make([]string, 1, ce.MaxCategories+1)
+	ce.indexToCategory[0] = UnknownCategory
+	for _, c := range p.Categories {
+		ce.addCategory(c)
+	}
+
+	if len(ce.Name) == 0 {
+		ce.Name = fmt.Sprintf("category[%v]", ce.MaxCategories)
+	}
+
+	return ce
+}
+
+//Assigns the next free slot to category, returns its index
+func (ce *CategoryEncoder) addCategory(category string) int {
+	idx := len(ce.indexToCategory)
+	ce.indexToCategory = append(ce.indexToCategory, category)
+	ce.categoryToIndex[category] = idx
+	return idx
+}
+
+/*
+	Returns the slot index of a category, learning it if allowed.
+Unknown categories map to slot 0.
+*/
+func (ce *CategoryEncoder) getCategoryIndex(input string, learn bool) int {
+	if idx, ok := ce.categoryToIndex[input]; ok {
+		return idx
+	}
+
+	if learn && ce.LearnCategories && len(ce.indexToCategory) <= ce.MaxCategories {
+		if ce.Verbosity > 0 {
+			fmt.Printf("Learned category %v \n", input)
+		}
+		return ce.addCategory(input)
+	}
+
+	return 0
+}
+
+/*
+ Returns bucket index for given input
+*/
+func (ce *CategoryEncoder) getBucketIndices(input string) []int {
+	return []int{ce.getCategoryIndex(input, false)}
+}
+
+//Returns the known categories, excluding the unknown slot
+func (ce *CategoryEncoder) CategoryList() []string {
+	result := make([]string, len(ce.indexToCategory)-1)
+	copy(result, ce.indexToCategory[1:])
+	return result
+}
+
+/*
+ Returns encoded input
+*/
+func (ce *CategoryEncoder) Encode(input string, learn bool) (output []bool) {
+	output = make([]bool, ce.n)
+	ce.EncodeToSlice(input, learn, output)
+	return output
+}
+
+/*
+	Encodes input to specified slice. Slice should be valid length
+*/
+func (ce *CategoryEncoder) EncodeToSlice(input string, learn bool, output []bool) {
+	idx := ce.getCategoryIndex(input, learn)
+	output = output[:ce.n]
+	utils.FillSliceBool(output, false)
+	utils.FillSliceRangeBool(output, true, idx*ce.Width, ce.Width)
+
+	if ce.Verbosity >= 2 {
+		fmt.Println("input:", input)
+		fmt.Printf("category: %v \n", idx)
+		fmt.Printf("output: %v \n", utils.Bool2Int(output))
+	}
+}
+
+/*
+	Return the interal topDownMappingM matrix, one row per category slot
+where each row contains the encoded output for that slot.
+*/
+func (ce *CategoryEncoder) getTopDownMapping() *htm.SparseBinaryMatrix {
+	//if already calculated return
+	if ce.topDownMappingM != nil {
+		return ce.topDownMappingM
+	}
+
+	numCategories := ce.MaxCategories + 1
+	ce.topDownMappingM = htm.NewSparseBinaryMatrix(numCategories, ce.n)
+
+	row := make([]bool, ce.n)
+	for i := 0; i < numCategories; i++ {
+		utils.FillSliceBool(row, false)
+		utils.FillSliceRangeBool(row, true, i*ce.Width, ce.Width)
+		ce.topDownMappingM.SetRowFromDense(i, row)
+	}
+
+	return ce.topDownMappingM
+}
+
+//Returns the name of a slot, unused slots are reported as unknown
+func (ce *CategoryEncoder) slotName(idx int) string {
+	if idx < len(ce.indexToCategory) {
+		return ce.indexToCategory[idx]
+	}
+	return UnknownCategory
+}
+
+/*
+	Returns category name and encoding of the specified bucket
+*/
+func (ce *CategoryEncoder) getBucketInfo(buckets []int) (value string, encoding []bool) {
+	category := buckets[0]
+	encoding = ce.getTopDownMapping().GetDenseRow(category)
+	return ce.slotName(category), encoding
+}
+
+/*
+	Returns the category that best matches the encoded input
+*/
+func (ce *CategoryEncoder) topDownCompute(encoded []bool) string {
+	decoded := ce.Decode(encoded)
+	if len(decoded) == 0 {
+		return UnknownCategory
+	}
+	return decoded[0]
+}
+
+/*
+	Decode an encoded sequence. Returns the categories that have any bits
+set, ranked by the number of matching bits.
+*/
+func (ce *CategoryEncoder) Decode(encoded []bool) []string {
+	comps := ce.getTopDownMapping().RowAndSum(encoded[:ce.n])
+
+	slots := make([]int, 0, len(comps))
+	for idx, overlap := range comps {
+		if overlap > 0 && idx < len(ce.indexToCategory) {
+			slots = append(slots, idx)
+		}
+	}
+
+	sort.SliceStable(slots, func(i, j int) bool {
+		return comps[slots[i]] > comps[slots[j]]
+	})
+
+	result := make([]string, len(slots))
+	for idx, slot := range slots {
+		result[idx] = ce.slotName(slot)
+	}
+
+	return result
+}
+
+//Returns the number of bits in the output
+func (ce *CategoryEncoder) OutputWidth() int {
+	return ce.n
+}
+
+/*
+	Encodes a category to specified slice, returns an error if value
+is not a string
+*/
+func (ce *CategoryEncoder) EncodeValueToSlice(value interface{}, learn bool, output []bool) error {
+	input, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("expected string value, got %T", value)
+	}
+	ce.EncodeToSlice(input, learn, output)
+	return nil
+}
+
+//Decodes encoded, returns the ranked category names
+func (ce *CategoryEncoder) DecodeValue(encoded []bool) interface{} {
+	return ce.Decode(encoded)
+}
+
+//Returns the name and bit range of the output
+func (ce *CategoryEncoder) Description() []EncoderField {
+	return []EncoderField{{Name: ce.Name, Offset: 0, Width: ce.n}}
+}
+
+//Returns the slot index of a category
+func (ce *CategoryEncoder) BucketIndices(value interface{}) ([]int, error) {
+	input, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected string value, got %T", value)
+	}
+	return ce.getBucketIndices(input), nil
+}
+
+//Returns category name and encoding of the specified slot, the slot
+//index is used as scalar
+func (ce *CategoryEncoder) BucketInfo(buckets []int) []EncoderResult {
+	value, encoding := ce.getBucketInfo(buckets)
+	return []EncoderResult{{Value: value, Scalar: float64(buckets[0]), Encoding: encoding}}
+}
+
+//Returns 1 if both categories are equal, 0 otherwise
+func (ce *CategoryEncoder) Closeness(expected interface{}, actual interface{}) ([]float64, error) {
+	expVal, ok := expected.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected string value, got %T", expected)
+	}
+	actVal, ok := actual.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected string value, got %T", actual)
+	}
+	if expVal == actVal {
+		return []float64{1.0}, nil
+	}
+	return []float64{0.0}, nil
+}
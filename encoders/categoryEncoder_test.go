@@ -0,0 +1,78 @@
+package encoders
+
+import (
+	"github.com/nupic-community/htm/utils"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCategoryEncoding(t *testing.T) {
+	p := NewCategoryEncoderParams(3, []string{"ES", "GB", "US"})
+	e := NewCategoryEncoder(p)
+
+	assert.Equal(t, []string{"ES", "GB", "US"}, e.CategoryList())
+
+	expected := utils.Make1DBool([]int{0, 0, 0, 1, 1, 1, 0, 0, 0, 0, 0, 0})
+	assert.Equal(t, expected, e.Encode("ES", false))
+
+	expected = utils.Make1DBool([]int{0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1})
+	assert.Equal(t, expected, e.Encode("US", true))
+
+	// Unknown categories map to the first block
+	expected = utils.Make1DBool([]int{1, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0})
+	assert.Equal(t, expected, e.Encode("NA", true))
+	assert.Equal(t, []int{0}, e.getBucketIndices("NA"))
+	assert.Equal(t, []int{2}, e.getBucketIndices("GB"))
+}
+
+func TestCategoryEncoderLearning(t *testing.T) {
+	p := NewCategoryEncoderParams(2, []string{"a"})
+	p.MaxCategories = 2
+	p.LearnCategories = true
+	e := NewCategoryEncoder(p)
+
+	// Not learned without learn flag
+	assert.Equal(t, UnknownCategory, e.topDownCompute(e.Encode("b", false)))
+
+	encoded := e.Encode("b", true)
+	assert.Equal(t, 6, len(encoded))
+	assert.Equal(t, utils.Make1DBool([]int{0, 0, 0, 0, 1, 1}), encoded)
+	assert.Equal(t, []string{"a", "b"}, e.CategoryList())
+
+	// Out of slots
+	assert.Equal(t, UnknownCategory, e.topDownCompute(e.Encode("c", true)))
+	assert.Equal(t, []string{"a", "b"}, e.CategoryList())
+}
+
+func TestCategoryDecoding(t *testing.T) {
+	p := NewCategoryEncoderParams(3, []string{"ES", "GB", "US"})
+	e := NewCategoryEncoder(p)
+
+	assert.Equal(t, []string{"GB"}, e.Decode(e.Encode("GB", false)))
+	assert.Equal(t, "GB", e.topDownCompute(e.Encode("GB", false)))
+
+	// Ranked by number of matching bits
+	encoded := utils.Make1DBool([]int{0, 0, 0, 1, 0, 0, 0, 0, 0, 1, 1, 1})
+	assert.Equal(t, []string{"US", "ES"}, e.Decode(encoded))
+
+	assert.Equal(t, []string{}, e.Decode(make([]bool, 12)))
+
+	value, encoding := e.getBucketInfo([]int{1})
+	assert.Equal(t, "ES", value)
+	assert.Equal(t, e.Encode("ES", false), encoding)
+}
+
+func TestCategoryEncoderParamsValidate(t *testing.T) {
+	p := NewCategoryEncoderParams(3, []string{"a", "b"})
+	assert.Nil(t, p.Validate())
+
+	p.Categories = []string{"a", "a"}
+	assert.NotNil(t, p.Validate())
+
+	p = NewCategoryEncoderParams(0, []string{"a"})
+	assert.NotNil(t, p.Validate())
+
+	p = NewCategoryEncoderParams(3, []string{"a", "b"})
+	p.MaxCategories = 1
+	assert.NotNil(t, p.Validate())
+}
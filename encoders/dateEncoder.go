@@ -291,13 +291,131 @@ func (de *DateEncoder) Encode(date time.Time) []bool {
 	return output
 }
 
+//Sub encoder of a date encoder with the date attribute it encodes
+type dateSubEncoder struct {
+	encoder *ScalerEncoder
+	offset  int
+	scaler  func(date time.Time) float64
+}
+
 /*
- Encoder description
+	Returns the enabled sub encoders in output order
 */
-func (de *DateEncoder) Description(date time.Time) string {
-	return fmt.Sprintf("season %v ", de.seasonOffset) +
-		fmt.Sprintf(" day of week: %v", de.dayOfWeekOffset) +
-		fmt.Sprintf(" weekend: %v", de.weekendOffset) +
-		fmt.Sprintf(" holiday %v", de.holidayOffset) +
-		fmt.Sprintf(" time of day: %v ", de.timeOfDayOffset)
+func (de *DateEncoder) subEncoders() []dateSubEncoder {
+	result := make([]dateSubEncoder, 0, 5)
+	if de.seasonEncoder != nil {
+		result = append(result, dateSubEncoder{de.seasonEncoder, de.seasonOffset, de.getSeasonScaler})
+	}
+	if de.dayOfWeekEncoder != nil {
+		result = append(result, dateSubEncoder{de.dayOfWeekEncoder, de.dayOfWeekOffset, de.getDayOfWeekScaler})
+	}
+	if de.weekendEncoder != nil {
+		result = append(result, dateSubEncoder{de.weekendEncoder, de.weekendOffset, de.getWeekendScaler})
+	}
+	if de.holidayEncoder != nil {
+		result = append(result, dateSubEncoder{de.holidayEncoder, de.holidayOffset, de.getHolidayScaler})
+	}
+	if de.timeOfDayEncoder != nil {
+		result = append(result, dateSubEncoder{de.timeOfDayEncoder, de.timeOfDayOffset, de.getTimeOfDayScaler})
+	}
+	return result
+}
+
+func toTime(value interface{}) (time.Time, error) {
+	switch date := value.(type) {
+	case time.Time:
+		return date, nil
+	case *time.Time:
+		return *date, nil
+	}
+	return time.Time{}, fmt.Errorf("expected time.Time value, got %T", value)
+}
+
+//Returns the number of bits in the output
+func (de *DateEncoder) OutputWidth() int {
+	return de.width
+}
+
+/*
+	Encodes a time.Time to specified slice, returns an error if value
+is not a time
+*/
+func (de *DateEncoder) EncodeValueToSlice(value interface{}, learn bool, output []bool) error {
+	date, err := toTime(value)
+	if err != nil {
+		return err
+	}
+	utils.FillSliceBool(output[:de.width], false)
+	de.EncodeToSlice(date, output)
+	return nil
+}
+
+/*
+ Encoder description, returns the name and bit range of each sub encoder
+*/
+func (de *DateEncoder) Description() []EncoderField {
+	subs := de.subEncoders()
+	result := make([]EncoderField, len(subs))
+	for idx, sub := range subs {
+		result[idx] = EncoderField{Name: sub.encoder.Name, Offset: sub.offset, Width: sub.encoder.N}
+	}
+	return result
+}
+
+/*
+	Decodes each sub encoder, returns the decoded ranges keyed by sub
+encoder name
+*/
+func (de *DateEncoder) DecodeValue(encoded []bool) interface{} {
+	result := make(map[string][]utils.TupleFloat)
+	for _, sub := range de.subEncoders() {
+		//decoding modifies its input
+		subEncoded := make([]bool, sub.encoder.N)
+		copy(subEncoded, encoded[sub.offset:sub.offset+sub.encoder.N])
+		result[sub.encoder.Name] = sub.encoder.Decode(subEncoded)
+	}
+	return result
+}
+
+//Returns the bucket index of a date for each sub encoder
+func (de *DateEncoder) BucketIndices(value interface{}) ([]int, error) {
+	date, err := toTime(value)
+	if err != nil {
+		return nil, err
+	}
+	subs := de.subEncoders()
+	result := make([]int, len(subs))
+	for idx, sub := range subs {
+		result[idx] = sub.encoder.getBucketIndices(sub.scaler(date))[0]
+	}
+	return result, nil
+}
+
+//Returns value and encoding of the specified buckets, one per sub encoder
+func (de *DateEncoder) BucketInfo(buckets []int) []EncoderResult {
+	subs := de.subEncoders()
+	result := make([]EncoderResult, len(subs))
+	for idx, sub := range subs {
+		result[idx] = sub.encoder.BucketInfo(buckets[idx : idx+1])[0]
+	}
+	return result
+}
+
+//Returns the closeness of each sub encoder scaler
+func (de *DateEncoder) Closeness(expected interface{}, actual interface{}) ([]float64, error) {
+	expDate, err := toTime(expected)
+	if err != nil {
+		return nil, err
+	}
+	actDate, err := toTime(actual)
+	if err != nil {
+		return nil, err
+	}
+	subs := de.subEncoders()
+	result := make([]float64, len(subs))
+	for idx, sub := range subs {
+		scores, _ := sub.encoder.Closeness(sub.scaler(expDate), sub.scaler(actDate))
+		result[idx] = scores[0]
+	}
+	return result, nil
 }
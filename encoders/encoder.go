@@ -0,0 +1,87 @@
+package encoders
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+/*
+	Common interface of all encoders. Values are passed as interface{} so
+encoders of different input types can be used interchangeably, methods
+taking a value return an error if the encoder can not handle its type or
+the value itself, eg a value outside the range of a scaler encoder that
+does not clip its input.
+*/
+type Encoder interface {
+	//Number of bits in the output
+	OutputWidth() int
+	//Encodes value to the first OutputWidth() bits of output
+	EncodeValueToSlice(value interface{}, learn bool, output []bool) error
+	//Name and bit range of each sub field of the output
+	Description() []EncoderField
+	//Decodes the first OutputWidth() bits of encoded
+	DecodeValue(encoded []bool) interface{}
+	//Returns the bucket of value for each sub field
+	BucketIndices(value interface{}) ([]int, error)
+	//Returns value and encoding of each of the specified buckets
+	BucketInfo(buckets []int) []EncoderResult
+	//Returns a score between 0 and 1 for each sub field of how close
+	//actual is to expected
+	Closeness(expected interface{}, actual interface{}) ([]float64, error)
+}
+
+//Name and bit range of a field in the output of an encoder
+type EncoderField struct {
+	Name   string
+	Offset int
+	Width  int
+}
+
+//Value, scalar representation and encoding of a bucket
+type EncoderResult struct {
+	Value    interface{}
+	Scalar   float64
+	Encoding []bool
+}
+
+var _ Encoder = (*ScalerEncoder)(nil)
+var _ Encoder = (*DateEncoder)(nil)
+var _ Encoder = (*RandomDistributedScalarEncoder)(nil)
+var _ Encoder = (*CategoryEncoder)(nil)
+var _ Encoder = (*MultiEncoder)(nil)
+
+/*
+	Returns the input dimensions of a spatial pooler fed by the
+specified encoder
+*/
+func InputDimensions(e Encoder) []int {
+	return []int{e.OutputWidth()}
+}
+
+//Converts numeric values to float64
+func toFloat64(value interface{}) (float64, error) {
+	val := reflect.ValueOf(value)
+	switch val.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return val.Float(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(val.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(val.Uint()), nil
+	}
+	return 0, fmt.Errorf("expected numeric value, got %T", value)
+}
+
+/*
+	Closeness of two unbounded scalars, the error is taken relative to
+the larger of both values
+*/
+func scalarCloseness(expected, actual float64) float64 {
+	err := math.Abs(expected - actual)
+	denom := math.Max(math.Abs(expected), math.Abs(actual))
+	if denom == 0 {
+		denom = 1
+	}
+	return math.Max(0, 1-err/denom)
+}
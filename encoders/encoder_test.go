@@ -0,0 +1,174 @@
+package encoders
+
+import (
+	"github.com/nupic-community/htm"
+	"github.com/nupic-community/htm/utils"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestScalerEncoderInterface(t *testing.T) {
+	p := NewScalerEncoderParams(3, 0, 10)
+	p.Resolution = 1
+	p.Name = "scaler"
+	var e Encoder = NewScalerEncoder(p)
+
+	assert.Equal(t, 13, e.OutputWidth())
+	assert.Equal(t, []EncoderField{{Name: "scaler", Offset: 0, Width: 13}}, e.Description())
+
+	buckets, err := e.BucketIndices(4)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{4}, buckets)
+
+	info := e.BucketInfo(buckets)
+	assert.Equal(t, 1, len(info))
+	assert.Equal(t, 4.0, info[0].Value)
+	output := make([]bool, e.OutputWidth())
+	assert.Nil(t, e.EncodeValueToSlice(4, false, output))
+	assert.Equal(t, output, info[0].Encoding)
+
+	scores, err := e.Closeness(4, 6)
+	assert.Nil(t, err)
+	assert.InDelta(t, 0.8, scores[0], 1e-9)
+
+	_, err = e.BucketIndices("4")
+	assert.NotNil(t, err)
+}
+
+func TestScalerEncoderInterfaceResolution(t *testing.T) {
+	p := NewScalerEncoderParams(3, 0, 10)
+	p.Resolution = 0.5
+	var e Encoder = NewScalerEncoder(p)
+
+	buckets, err := e.BucketIndices(7.5)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{15}, buckets)
+
+	output := make([]bool, e.OutputWidth())
+	assert.Nil(t, e.EncodeValueToSlice(7.5, false, output))
+	info := e.BucketInfo(buckets)
+	assert.Equal(t, 7.5, info[0].Value)
+	assert.Equal(t, output, info[0].Encoding)
+	assert.Equal(t, []utils.TupleFloat{{A: 7.5, B: 7.5}}, e.DecodeValue(output))
+
+	// Last bucket is the max value
+	info = e.BucketInfo([]int{20})
+	assert.Equal(t, 10.0, info[0].Value)
+}
+
+func TestScalerEncoderInterfaceOutOfRange(t *testing.T) {
+	p := NewScalerEncoderParams(3, 0, 10)
+	p.Resolution = 1
+	var e Encoder = NewScalerEncoder(p)
+	output := make([]bool, e.OutputWidth())
+
+	for _, value := range []float64{-1, 11, math.NaN()} {
+		assert.NotNil(t, e.EncodeValueToSlice(value, false, output))
+		_, err := e.BucketIndices(value)
+		assert.NotNil(t, err)
+	}
+	assert.Nil(t, e.EncodeValueToSlice(10, false, output))
+
+	// clipped input is accepted
+	p.ClipInput = true
+	e = NewScalerEncoder(p)
+	assert.Nil(t, e.EncodeValueToSlice(11, false, output))
+	buckets, err := e.BucketIndices(-1)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0}, buckets)
+
+	// periodic input must be below MaxVal even when clipping
+	p.Periodic = true
+	e = NewScalerEncoder(p)
+	assert.NotNil(t, e.EncodeValueToSlice(10, false, make([]bool, e.OutputWidth())))
+	_, err = e.BucketIndices(-1)
+	assert.NotNil(t, err)
+
+	// multi encoders return the error instead of panicking
+	me := NewMultiEncoder("multi")
+	p = NewScalerEncoderParams(3, 0, 10)
+	p.Resolution = 1
+	assert.Nil(t, me.AddEncoder("value", NewScalerEncoder(p)))
+	_, err = me.Encode(map[string]interface{}{"value": 20.0}, false)
+	assert.NotNil(t, err)
+}
+
+func TestPeriodicScalerEncoderCloseness(t *testing.T) {
+	p := NewScalerEncoderParams(3, 0, 10)
+	p.Radius = 1.5
+	p.Periodic = true
+	e := NewScalerEncoder(p)
+
+	// Error is measured the short way around
+	scores, err := e.Closeness(1, 9)
+	assert.Nil(t, err)
+	assert.InDelta(t, 0.8, scores[0], 1e-9)
+
+	info := e.BucketInfo([]int{0})
+	assert.Equal(t, e.OutputWidth(), len(info[0].Encoding))
+}
+
+func TestDateEncoderInterface(t *testing.T) {
+	p := NewDateEncoderParams()
+	var e Encoder = NewDateEncoder(p)
+	d := time.Date(2010, 11, 4, 14, 55, 0, 0, time.UTC)
+
+	desc := e.Description()
+	assert.Equal(t, 4, len(desc))
+	assert.Equal(t, "Season", desc[0].Name)
+	assert.Equal(t, 0, desc[0].Offset)
+	width := 0
+	for _, field := range desc {
+		assert.Equal(t, width, field.Offset)
+		width += field.Width
+	}
+	assert.Equal(t, e.OutputWidth(), width)
+
+	output := make([]bool, e.OutputWidth())
+	assert.Nil(t, e.EncodeValueToSlice(d, true, output))
+	assert.Equal(t, NewDateEncoder(p).Encode(d), output)
+	assert.NotNil(t, e.EncodeValueToSlice(42, true, output))
+
+	buckets, err := e.BucketIndices(d)
+	assert.Nil(t, err)
+	assert.Equal(t, len(desc), len(buckets))
+	assert.Equal(t, len(desc), len(e.BucketInfo(buckets)))
+
+	scores, err := e.Closeness(d, d)
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{1, 1, 1, 1}, scores)
+}
+
+func TestMultiEncoderInterface(t *testing.T) {
+	me := NewMultiEncoder("multi")
+	assert.Nil(t, me.AddEncoder("date", NewDateEncoder(NewDateEncoderParams())))
+	assert.Nil(t, me.AddEncoder("kind", NewCategoryEncoder(NewCategoryEncoderParams(2, []string{"a", "b"}))))
+
+	desc := me.Description()
+	assert.Equal(t, 5, len(desc))
+	assert.Equal(t, "date.Season", desc[0].Name)
+	assert.Equal(t, "kind", desc[4].Name)
+
+	record := map[string]interface{}{"date": time.Date(2010, 11, 4, 14, 55, 0, 0, time.UTC), "kind": "b"}
+	buckets, err := me.BucketIndices(record)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(buckets))
+	info := me.BucketInfo(buckets)
+	assert.Equal(t, "b", info[4].Value)
+
+	other := map[string]interface{}{"date": record["date"], "kind": "a"}
+	scores, err := me.Closeness(record, other)
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{1, 1, 1, 1, 0}, scores)
+}
+
+func TestInputDimensions(t *testing.T) {
+	e := NewCategoryEncoder(NewCategoryEncoderParams(5, []string{"a", "b", "c"}))
+	spParams := htm.NewSpParams()
+	spParams.InputDimensions = InputDimensions(e)
+	spParams.ColumnDimensions = []int{10}
+	assert.Equal(t, []int{20}, spParams.InputDimensions)
+	assert.Nil(t, spParams.Validate())
+}
@@ -0,0 +1,250 @@
+package encoders
+
+import (
+	"fmt"
+	"github.com/nupic-community/htm"
+	"reflect"
+)
+
+/*
+ A multi encoder encodes a record by encoding each of its fields with
+a named sub encoder and concatenating the results. Records are either
+a map[string]interface{} keyed by field name or a struct whose fields
+match the encoder names, a struct field can be mapped to a different
+name with the `encoder:"name"` tag.
+*/
+type MultiEncoder struct {
+	Name string
+
+	width    int
+	fields   []EncoderField
+	encoders []Encoder
+}
+
+/*
+	Intializes a new multi encoder, encoders are added with AddEncoder
+*/
+func NewMultiEncoder(name string) *MultiEncoder {
+	me := new(MultiEncoder)
+	me.Name = name
+	return me
+}
+
+/*
+	Appends a named sub encoder, its bits are placed after the bits of
+the encoders added before it
+*/
+func (me *MultiEncoder) AddEncoder(name string, encoder Encoder) error {
+	if len(name) == 0 {
+		return &htm.ParamError{Field: "name", Reason: "must not be empty"}
+	}
+	if me.fieldIndex(name) != -1 {
+		return &htm.ParamError{Field: "name", Reason: fmt.Sprintf("duplicate field %v", name)}
+	}
+
+	field := EncoderField{Name: name, Offset: me.width, Width: encoder.OutputWidth()}
+	me.fields = append(me.fields, field)
+	me.encoders = append(me.encoders, encoder)
+	me.width += field.Width
+
+	return nil
+}
+
+func (me *MultiEncoder) fieldIndex(name string) int {
+	for idx, field := range me.fields {
+		if field.Name == name {
+			return idx
+		}
+	}
+	return -1
+}
+
+//Returns the total number of bits in the output
+func (me *MultiEncoder) OutputWidth() int {
+	return me.width
+}
+
+//Returns the name and bit range of each field in output order
+func (me *MultiEncoder) Fields() []EncoderField {
+	result := make([]EncoderField, len(me.fields))
+	copy(result, me.fields)
+	return result
+}
+
+//Returns the bit range of the named field
+func (me *MultiEncoder) FieldRange(name string) (offset int, width int, ok bool) {
+	idx := me.fieldIndex(name)
+	if idx == -1 {
+		return 0, 0, false
+	}
+	return me.fields[idx].Offset, me.fields[idx].Width, true
+}
+
+/*
+	Returns the value of every field in the record keyed by field name
+*/
+func (me *MultiEncoder) recordValues(record interface{}) (map[string]interface{}, error) {
+	if values, ok := record.(map[string]interface{}); ok {
+		return values, nil
+	}
+
+	val := reflect.ValueOf(record)
+	for val.Kind() == reflect.Ptr && !val.IsNil() {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unsupported record type %T", record)
+	}
+
+	typ := val.Type()
+	values := make(map[string]interface{}, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if len(sf.PkgPath) != 0 {
+			//unexported
+			continue
+		}
+		name := sf.Name
+		if tag := sf.Tag.Get("encoder"); len(tag) != 0 {
+			name = tag
+		}
+		values[name] = val.Field(i).Interface()
+	}
+
+	return values, nil
+}
+
+/*
+ Returns encoded record
+*/
+func (me *MultiEncoder) Encode(record interface{}, learn bool) ([]bool, error) {
+	output := make([]bool, me.width)
+	err := me.EncodeToSlice(record, learn, output)
+	return output, err
+}
+
+/*
+	Encodes record to specified slice. Slice should be valid length
+*/
+func (me *MultiEncoder) EncodeToSlice(record interface{}, learn bool, output []bool) error {
+	values, err := me.recordValues(record)
+	if err != nil {
+		return err
+	}
+
+	for idx, field := range me.fields {
+		value, ok := values[field.Name]
+		if !ok {
+			return fmt.Errorf("record is missing field %v", field.Name)
+		}
+		fieldOutput := output[field.Offset : field.Offset+field.Width]
+		if err := me.encoders[idx].EncodeValueToSlice(value, learn, fieldOutput); err != nil {
+			return fmt.Errorf("field %v: %v", field.Name, err)
+		}
+	}
+
+	return nil
+}
+
+/*
+	Decodes each field of an encoded record, returns the decoded values
+keyed by field name
+*/
+func (me *MultiEncoder) Decode(encoded []bool) map[string]interface{} {
+	result := make(map[string]interface{}, len(me.fields))
+	for idx, field := range me.fields {
+		//sub encoders may modify their input while decoding
+		fieldEncoded := make([]bool, field.Width)
+		copy(fieldEncoded, encoded[field.Offset:field.Offset+field.Width])
+		result[field.Name] = me.encoders[idx].DecodeValue(fieldEncoded)
+	}
+	return result
+}
+
+//Encodes record to specified slice
+func (me *MultiEncoder) EncodeValueToSlice(value interface{}, learn bool, output []bool) error {
+	return me.EncodeToSlice(value, learn, output)
+}
+
+//Decodes encoded, returns the decoded values keyed by field name
+func (me *MultiEncoder) DecodeValue(encoded []bool) interface{} {
+	return me.Decode(encoded)
+}
+
+/*
+	Returns the sub fields of every field in output order. Fields with
+a single sub field keep their name, others are named field.subfield.
+*/
+func (me *MultiEncoder) Description() []EncoderField {
+	result := make([]EncoderField, 0, len(me.fields))
+	for idx, field := range me.fields {
+		subs := me.encoders[idx].Description()
+		for _, sub := range subs {
+			name := field.Name
+			if len(subs) > 1 {
+				name = field.Name + "." + sub.Name
+			}
+			result = append(result, EncoderField{Name: name, Offset: field.Offset + sub.Offset, Width: sub.Width})
+		}
+	}
+	return result
+}
+
+//Returns the bucket indices of all fields of the record
+func (me *MultiEncoder) BucketIndices(value interface{}) ([]int, error) {
+	values, err := me.recordValues(value)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]int, 0, len(me.fields))
+	for idx, field := range me.fields {
+		fieldValue, ok := values[field.Name]
+		if !ok {
+			return nil, fmt.Errorf("record is missing field %v", field.Name)
+		}
+		buckets, err := me.encoders[idx].BucketIndices(fieldValue)
+		if err != nil {
+			return nil, fmt.Errorf("field %v: %v", field.Name, err)
+		}
+		result = append(result, buckets...)
+	}
+	return result, nil
+}
+
+/*
+	Returns value and encoding of the specified buckets, buckets are
+split between fields by the number of sub fields of each encoder
+*/
+func (me *MultiEncoder) BucketInfo(buckets []int) []EncoderResult {
+	result := make([]EncoderResult, 0, len(buckets))
+	start := 0
+	for _, encoder := range me.encoders {
+		count := len(encoder.Description())
+		result = append(result, encoder.BucketInfo(buckets[start:start+count])...)
+		start += count
+	}
+	return result
+}
+
+//Returns the closeness scores of all fields of the records
+func (me *MultiEncoder) Closeness(expected interface{}, actual interface{}) ([]float64, error) {
+	expValues, err := me.recordValues(expected)
+	if err != nil {
+		return nil, err
+	}
+	actValues, err := me.recordValues(actual)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]float64, 0, len(me.fields))
+	for idx, field := range me.fields {
+		scores, err := me.encoders[idx].Closeness(expValues[field.Name], actValues[field.Name])
+		if err != nil {
+			return nil, fmt.Errorf("field %v: %v", field.Name, err)
+		}
+		result = append(result, scores...)
+	}
+	return result, nil
+}
@@ -0,0 +1,70 @@
+package encoders
+
+import (
+	"github.com/nupic-community/htm/utils"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newTestMultiEncoder(t *testing.T) *MultiEncoder {
+	sp := NewScalerEncoderParams(3, 1, 8)
+	sp.Radius = 1.5
+	sp.Periodic = true
+
+	me := NewMultiEncoder("test")
+	assert.Nil(t, me.AddEncoder("value", NewScalerEncoder(sp)))
+	assert.Nil(t, me.AddEncoder("kind", NewCategoryEncoder(NewCategoryEncoderParams(2, []string{"a", "b"}))))
+	return me
+}
+
+func TestEncoderFields(t *testing.T) {
+	me := newTestMultiEncoder(t)
+
+	assert.Equal(t, 20, me.OutputWidth())
+	expected := []EncoderField{
+		{Name: "value", Offset: 0, Width: 14},
+		{Name: "kind", Offset: 14, Width: 6},
+	}
+	assert.Equal(t, expected, me.Fields())
+
+	offset, width, ok := me.FieldRange("kind")
+	assert.True(t, ok)
+	assert.Equal(t, 14, offset)
+	assert.Equal(t, 6, width)
+
+	_, _, ok = me.FieldRange("missing")
+	assert.False(t, ok)
+
+	assert.NotNil(t, me.AddEncoder("kind", NewCategoryEncoder(NewCategoryEncoderParams(2, []string{"c"}))))
+}
+
+func TestMultiEncoderEncode(t *testing.T) {
+	me := newTestMultiEncoder(t)
+
+	encoded, err := me.Encode(map[string]interface{}{"value": 2, "kind": "b"}, false)
+	assert.Nil(t, err)
+	expected := utils.Make1DBool([]int{0, 1, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 1, 1})
+	assert.Equal(t, expected, encoded)
+
+	type record struct {
+		Value float64 `encoder:"value"`
+		Kind  string  `encoder:"kind"`
+	}
+	encoded2, err := me.Encode(record{Value: 2, Kind: "b"}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, encoded, encoded2)
+
+	decoded := me.Decode(encoded)
+	assert.Equal(t, []utils.TupleFloat{{A: 2, B: 2}}, decoded["value"])
+	assert.Equal(t, []string{"b"}, decoded["kind"])
+
+	_, err = me.Encode(map[string]interface{}{"value": 2}, false)
+	assert.NotNil(t, err)
+
+	_, err = me.Encode(map[string]interface{}{"value": "2", "kind": "b"}, false)
+	assert.NotNil(t, err)
+
+	_, err = me.Encode(42, false)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,418 @@
+package encoders
+
+import (
+	"fmt"
+	"github.com/nupic-community/htm"
+	"github.com/nupic-community/htm/utils"
+	"math"
+	"math/rand"
+)
+
+//Maximum number of attempts at finding a valid representation for
+//a new bucket
+const rdseMaxRetries = 10000
+
+/*
+	Params for the random distributed scalar encoder
+
+resolution -- Two inputs separated by greater than, or equal to the resolution
+are guaranteed to have different representations.
+
+offset -- Center of the first bucket, if NaN the first encoded input is used.
+*/
+type RandomDistributedScalarEncoderParams struct {
+	Resolution float64
+	Width      int
+	N          int
+	Name       string
+	Offset     float64
+	Seed       int
+	MaxBuckets int
+	Verbosity  int
+}
+
+func NewRandomDistributedScalarEncoderParams(resolution float64) *RandomDistributedScalarEncoderParams {
+	p := new(RandomDistributedScalarEncoderParams)
+
+	p.Resolution = resolution
+	p.Width = 21
+	p.N = 400
+	p.Name = ""
+	p.Offset = math.NaN()
+	p.Seed = 42
+	p.MaxBuckets = 1000
+	p.Verbosity = 0
+
+	return p
+}
+
+//Validates params, returns a *htm.ParamError naming the first invalid field
+func (p *RandomDistributedScalarEncoderParams) Validate() error {
+	if p.Resolution <= 0 {
+		return &htm.ParamError{Field: "Resolution", Reason: "must be greater than 0"}
+	}
+	if p.Width <= 0 || p.Width%2 == 0 {
+		return &htm.ParamError{Field: "Width", Reason: "must be an odd positive number"}
+	}
+	if p.N <= 6*p.Width {
+		return &htm.ParamError{Field: "N", Reason: "must be greater than 6 * Width"}
+	}
+	if p.MaxBuckets < 1 {
+		return &htm.ParamError{Field: "MaxBuckets", Reason: "must be greater than 0"}
+	}
+	return nil
+}
+
+/*
+ A scalar encoder that encodes a numeric value into a random distributed
+representation. Inputs are grouped into buckets of size resolution, each
+bucket is represented by Width randomly chosen on bits out of N. Adjacent
+buckets share all but one bit, buckets further than Width apart share at
+most a few bits.
+
+Unlike the ScalerEncoder no range has to be specified up front, buckets
+are created on demand as new values are seen.
+*/
+type RandomDistributedScalarEncoder struct {
+	RandomDistributedScalarEncoderParams
+
+	rng        *rand.Rand
+	maxOverlap int
+	minIndex   int
+	maxIndex   int
+	//bucket index -> on bits
+	bucketMap map[int][]int
+}
+
+/*
+	Intializes a new random distributed scalar encoder
+*/
+func NewRandomDistributedScalarEncoder(p *RandomDistributedScalarEncoderParams) *RandomDistributedScalarEncoder {
+	if err := p.Validate(); err != nil {
+		panic(err)
+	}
+
+	rdse := new(RandomDistributedScalarEncoder)
+	rdse.RandomDistributedScalarEncoderParams = *p
+	rdse.rng = rand.New(utils.NewRandSource(int64(p.Seed)))
+	rdse.maxOverlap = 2
+
+	if len(rdse.Name) == 0 {
+		rdse.Name = fmt.Sprintf("[%v]", rdse.Resolution)
+	}
+
+	rdse.initializeBucketMap()
+
+	return rdse
+}
+
+/*
+	Creates the representation of the middle bucket, all other buckets
+are derived from it.
+*/
+func (rdse *RandomDistributedScalarEncoder) initializeBucketMap() {
+	middle := rdse.MaxBuckets / 2
+	rdse.bucketMap = make(map[int][]int, rdse.MaxBuckets)
+	rdse.bucketMap[middle] = rdse.rng.Perm(rdse.N)[:rdse.Width]
+	rdse.minIndex = middle
+	rdse.maxIndex = middle
+}
+
+/*
+ Returns bucket index for given input. Inputs beyond the buckets,
+including infinite ones, map to the first or last bucket. NaN maps to the
+middle bucket and does not set the offset.
+*/
+func (rdse *RandomDistributedScalarEncoder) getBucketIndices(input float64) []int {
+	middle := rdse.MaxBuckets / 2
+	if math.IsNaN(input) {
+		return []int{middle}
+	}
+	if math.IsNaN(rdse.Offset) && !math.IsInf(input, 0) {
+		rdse.Offset = input
+	}
+
+	// clamp before converting, huge offsets overflow int
+	offset := input
+	if !math.IsInf(input, 0) {
+		offset = utils.RoundPrec((input-rdse.Offset)/rdse.Resolution, 0)
+	}
+	offset = math.Max(float64(-middle), math.Min(offset, float64(rdse.MaxBuckets-1-middle)))
+
+	return []int{middle + int(offset)}
+}
+
+/*
+ Returns encoded input
+*/
+func (rdse *RandomDistributedScalarEncoder) Encode(input float64, learn bool) (output []bool) {
+	output = make([]bool, rdse.N)
+	rdse.EncodeToSlice(input, learn, output)
+	return output
+}
+
+/*
+	Encodes input to specified slice. Slice should be valid length
+*/
+func (rdse *RandomDistributedScalarEncoder) EncodeToSlice(input float64, learn bool, output []bool) {
+	bucketIdx := rdse.getBucketIndices(input)[0]
+	output = output[:rdse.N]
+	utils.FillSliceBool(output, false)
+	utils.SetIdxBool(output, rdse.mapBucketIndexToNonZeroBits(bucketIdx), true)
+
+	if rdse.Verbosity >= 2 {
+		fmt.Println("input:", input)
+		fmt.Printf("bucket: %v \n", bucketIdx)
+		fmt.Printf("output: %v \n", utils.OnIndices(output))
+	}
+}
+
+/*
+	Returns the on bits of the specified bucket, creating it
+if needed
+*/
+func (rdse *RandomDistributedScalarEncoder) mapBucketIndexToNonZeroBits(index int) []int {
+	if index < 0 {
+		index = 0
+	} else if index >= rdse.MaxBuckets {
+		index = rdse.MaxBuckets - 1
+	}
+
+	if _, ok := rdse.bucketMap[index]; !ok {
+		rdse.createBucket(index)
+	}
+
+	return rdse.bucketMap[index]
+}
+
+/*
+	Creates the bucket at index and all buckets between it and the
+existing ones
+*/
+func (rdse *RandomDistributedScalarEncoder) createBucket(index int) {
+	if index < rdse.minIndex {
+		if index != rdse.minIndex-1 {
+			rdse.createBucket(index + 1)
+		}
+		rdse.bucketMap[index] = rdse.newRepresentation(rdse.minIndex, index)
+		rdse.minIndex = index
+	} else if index > rdse.maxIndex {
+		if index != rdse.maxIndex+1 {
+			rdse.createBucket(index - 1)
+		}
+		rdse.bucketMap[index] = rdse.newRepresentation(rdse.maxIndex, index)
+		rdse.maxIndex = index
+	}
+}
+
+/*
+	Derives a representation for newIndex from the representation of the
+adjacent bucket at index by replacing a single bit
+*/
+func (rdse *RandomDistributedScalarEncoder) newRepresentation(index int, newIndex int) []int {
+	prev := rdse.bucketMap[index]
+	newRep := make([]int, len(prev))
+	copy(newRep, prev)
+
+	// Replace the bit that was least recently added so the overlap with
+	// more distant buckets keeps decreasing
+	ri := utils.Mod(newIndex, rdse.Width)
+	for tries := 0; ; tries++ {
+		if tries > rdseMaxRetries {
+			panic(fmt.Sprintf("Could not find a valid representation for bucket %v", newIndex))
+		}
+		newBit := rdse.rng.Intn(rdse.N)
+		if utils.ContainsInt(newBit, prev) {
+			continue
+		}
+		newRep[ri] = newBit
+		if rdse.newRepresentationOK(newRep, newIndex) {
+			break
+		}
+	}
+
+	return newRep
+}
+
+/*
+	Returns true if the overlap of newRep with every existing bucket
+is acceptable
+*/
+func (rdse *RandomDistributedScalarEncoder) newRepresentationOK(newRep []int, newIndex int) bool {
+	for i := rdse.minIndex; i <= rdse.maxIndex; i++ {
+		overlap := countOverlap(newRep, rdse.bucketMap[i])
+		if !rdse.overlapOK(i, newIndex, overlap) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+	Buckets closer than Width apart must overlap in exactly Width minus their
+distance bits, buckets further apart in at most maxOverlap bits
+*/
+func (rdse *RandomDistributedScalarEncoder) overlapOK(i, j, overlap int) bool {
+	dist := i - j
+	if dist < 0 {
+		dist = -dist
+	}
+	if dist < rdse.Width {
+		return overlap == rdse.Width-dist
+	}
+	return overlap <= rdse.maxOverlap
+}
+
+//Returns the number of bits common to both representations
+func countOverlap(rep1, rep2 []int) int {
+	overlap := 0
+	for _, bit := range rep1 {
+		if utils.ContainsInt(bit, rep2) {
+			overlap++
+		}
+	}
+	return overlap
+}
+
+/*
+	Returns value and encoding of the specified bucket
+*/
+func (rdse *RandomDistributedScalarEncoder) getBucketInfo(buckets []int) (value float64, encoding []bool) {
+	bucketIdx := buckets[0]
+	value = rdse.bucketValue(bucketIdx)
+	encoding = make([]bool, rdse.N)
+	utils.SetIdxBool(encoding, rdse.mapBucketIndexToNonZeroBits(bucketIdx), true)
+	return value, encoding
+}
+
+//Returns the center value of a bucket
+func (rdse *RandomDistributedScalarEncoder) bucketValue(bucketIdx int) float64 {
+	offset := rdse.Offset
+	if math.IsNaN(offset) {
+		offset = 0
+	}
+	return offset + float64(bucketIdx-rdse.MaxBuckets/2)*rdse.Resolution
+}
+
+/*
+	Returns the value for each bucket created so far, ordered by bucket
+*/
+func (rdse *RandomDistributedScalarEncoder) getBucketValues() []float64 {
+	values := make([]float64, 0, rdse.maxIndex-rdse.minIndex+1)
+	for i := rdse.minIndex; i <= rdse.maxIndex; i++ {
+		values = append(values, rdse.bucketValue(i))
+	}
+	return values
+}
+
+/*
+	Returns the bucket that best matches the encoded input and its overlap
+*/
+func (rdse *RandomDistributedScalarEncoder) bestBucket(encoded []bool) (bucketIdx int, overlap int) {
+	onBits := utils.OnIndices(encoded[:rdse.N])
+	bucketIdx = rdse.minIndex
+	overlap = -1
+	for i := rdse.minIndex; i <= rdse.maxIndex; i++ {
+		o := countOverlap(rdse.bucketMap[i], onBits)
+		if o > overlap {
+			bucketIdx = i
+			overlap = o
+		}
+	}
+	return bucketIdx, overlap
+}
+
+/*
+	top down compute
+*/
+func (rdse *RandomDistributedScalarEncoder) topDownCompute(encoded []bool) float64 {
+	bucketIdx, _ := rdse.bestBucket(encoded)
+	return rdse.bucketValue(bucketIdx)
+}
+
+/*
+	Decode an encoded sequence. Returns the value range of the bucket
+that best matches the encoding, or an empty slice if nothing matches.
+*/
+func (rdse *RandomDistributedScalarEncoder) Decode(encoded []bool) []utils.TupleFloat {
+	bucketIdx, overlap := rdse.bestBucket(encoded)
+	if overlap <= 0 {
+		return []utils.TupleFloat{}
+	}
+
+	val := rdse.bucketValue(bucketIdx)
+	return []utils.TupleFloat{{A: val, B: val}}
+}
+
+//Returns the number of bits in the output
+func (rdse *RandomDistributedScalarEncoder) OutputWidth() int {
+	return rdse.N
+}
+
+//Converts value to a float64 input, returns an error if value is not
+//numeric or NaN
+func (rdse *RandomDistributedScalarEncoder) inputValue(value interface{}) (float64, error) {
+	input, err := toFloat64(value)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(input) {
+		return 0, fmt.Errorf("encoder %v can not encode NaN", rdse.Name)
+	}
+	return input, nil
+}
+
+/*
+	Encodes a numeric value to specified slice, returns an error if value
+is not numeric or NaN
+*/
+func (rdse *RandomDistributedScalarEncoder) EncodeValueToSlice(value interface{}, learn bool, output []bool) error {
+	input, err := rdse.inputValue(value)
+	if err != nil {
+		return err
+	}
+	rdse.EncodeToSlice(input, learn, output)
+	return nil
+}
+
+//Decodes encoded, returns the decoded ranges
+func (rdse *RandomDistributedScalarEncoder) DecodeValue(encoded []bool) interface{} {
+	return rdse.Decode(encoded)
+}
+
+//Returns the name and bit range of the output
+func (rdse *RandomDistributedScalarEncoder) Description() []EncoderField {
+	return []EncoderField{{Name: rdse.Name, Offset: 0, Width: rdse.N}}
+}
+
+//Returns the bucket index of a numeric value, returns an error if value
+//is not numeric or NaN
+func (rdse *RandomDistributedScalarEncoder) BucketIndices(value interface{}) ([]int, error) {
+	input, err := rdse.inputValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return rdse.getBucketIndices(input), nil
+}
+
+//Returns value and encoding of the specified bucket
+func (rdse *RandomDistributedScalarEncoder) BucketInfo(buckets []int) []EncoderResult {
+	value, encoding := rdse.getBucketInfo(buckets)
+	return []EncoderResult{{Value: value, Scalar: value, Encoding: encoding}}
+}
+
+/*
+	Returns 1 minus the error between expected and actual relative to
+the larger of both, the encoder has no fixed range to compare against
+*/
+func (rdse *RandomDistributedScalarEncoder) Closeness(expected interface{}, actual interface{}) ([]float64, error) {
+	expVal, err := toFloat64(expected)
+	if err != nil {
+		return nil, err
+	}
+	actVal, err := toFloat64(actual)
+	if err != nil {
+		return nil, err
+	}
+	return []float64{scalarCloseness(expVal, actVal)}, nil
+}
@@ -0,0 +1,126 @@
+package encoders
+
+import (
+	"github.com/nupic-community/htm/utils"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func overlapBool(a, b []bool) int {
+	overlap := 0
+	for i := range a {
+		if a[i] && b[i] {
+			overlap++
+		}
+	}
+	return overlap
+}
+
+func TestRDSEEncoding(t *testing.T) {
+	p := NewRandomDistributedScalarEncoderParams(1.0)
+	e := NewRandomDistributedScalarEncoder(p)
+
+	e0 := e.Encode(0, false)
+	assert.Equal(t, 400, len(e0))
+	assert.Equal(t, 21, utils.CountTrue(e0))
+	assert.Equal(t, 0.0, e.Offset)
+
+	// Same bucket gives the same encoding
+	assert.Equal(t, e0, e.Encode(0.4, false))
+
+	// Adjacent buckets share all but one bit
+	e1 := e.Encode(1, false)
+	assert.Equal(t, 20, overlapBool(e0, e1))
+
+	// Overlap decreases with distance
+	e5 := e.Encode(5, false)
+	assert.Equal(t, 16, overlapBool(e0, e5))
+
+	// Distant buckets barely overlap
+	e50 := e.Encode(-50, false)
+	assert.True(t, overlapBool(e0, e50) <= 2)
+	assert.Equal(t, 21, utils.CountTrue(e50))
+}
+
+func TestRDSEBucketIndices(t *testing.T) {
+	p := NewRandomDistributedScalarEncoderParams(0.5)
+	p.Offset = 10
+	p.MaxBuckets = 100
+	e := NewRandomDistributedScalarEncoder(p)
+
+	assert.Equal(t, []int{50}, e.getBucketIndices(10))
+	assert.Equal(t, []int{51}, e.getBucketIndices(10.5))
+	assert.Equal(t, []int{48}, e.getBucketIndices(9))
+	// Out of range values use the edge buckets
+	assert.Equal(t, []int{0}, e.getBucketIndices(-1000))
+	assert.Equal(t, []int{99}, e.getBucketIndices(1000))
+
+	// Huge and infinite values must not overflow
+	assert.Equal(t, []int{99}, e.getBucketIndices(math.MaxFloat64))
+	assert.Equal(t, []int{0}, e.getBucketIndices(-math.MaxFloat64))
+	assert.Equal(t, []int{99}, e.getBucketIndices(math.Inf(1)))
+	assert.Equal(t, []int{0}, e.getBucketIndices(math.Inf(-1)))
+	assert.Equal(t, 21, utils.CountTrue(e.Encode(math.Inf(1), false)))
+
+	// NaN is rejected by the encoder interface
+	_, err := e.BucketIndices(math.NaN())
+	assert.NotNil(t, err)
+	assert.NotNil(t, e.EncodeValueToSlice(math.NaN(), false, make([]bool, e.N)))
+	assert.Equal(t, []int{50}, e.getBucketIndices(math.NaN()))
+
+	// Infinite values do not set the offset
+	p.Offset = math.NaN()
+	e = NewRandomDistributedScalarEncoder(p)
+	assert.Equal(t, []int{99}, e.getBucketIndices(math.Inf(1)))
+	assert.True(t, math.IsNaN(e.Offset))
+	assert.Equal(t, []int{50}, e.getBucketIndices(3))
+	assert.Equal(t, 3.0, e.Offset)
+}
+
+func TestRDSESeed(t *testing.T) {
+	p := NewRandomDistributedScalarEncoderParams(1.0)
+	e1 := NewRandomDistributedScalarEncoder(p)
+	e2 := NewRandomDistributedScalarEncoder(p)
+	assert.Equal(t, e1.Encode(23, false), e2.Encode(23, false))
+	assert.Equal(t, e1.Encode(7, false), e2.Encode(7, false))
+
+	p.Seed = 7
+	e3 := NewRandomDistributedScalarEncoder(p)
+	assert.NotEqual(t, e1.Encode(23, false), e3.Encode(23, false))
+}
+
+func TestRDSEDecode(t *testing.T) {
+	p := NewRandomDistributedScalarEncoderParams(0.5)
+	e := NewRandomDistributedScalarEncoder(p)
+
+	for i := 0; i < 20; i++ {
+		e.Encode(float64(i), true)
+	}
+
+	encoded := e.Encode(4.5, false)
+	assert.Equal(t, []utils.TupleFloat{{A: 4.5, B: 4.5}}, e.Decode(encoded))
+	assert.Equal(t, 4.5, e.topDownCompute(encoded))
+
+	val, encoding := e.getBucketInfo(e.getBucketIndices(3))
+	assert.Equal(t, 3.0, val)
+	assert.Equal(t, e.Encode(3, false), encoding)
+
+	assert.Equal(t, []utils.TupleFloat{}, e.Decode(make([]bool, p.N)))
+	assert.Equal(t, 39, len(e.getBucketValues()))
+}
+
+func TestRDSEParamsValidate(t *testing.T) {
+	p := NewRandomDistributedScalarEncoderParams(1.0)
+	assert.Nil(t, p.Validate())
+
+	p.Width = 20
+	assert.NotNil(t, p.Validate())
+
+	p = NewRandomDistributedScalarEncoderParams(0)
+	assert.NotNil(t, p.Validate())
+
+	p = NewRandomDistributedScalarEncoderParams(1.0)
+	p.N = 100
+	assert.NotNil(t, p.Validate())
+}
@@ -86,14 +86,44 @@ type ScalerEncoder struct {
 	nInternal int
 }
 
+//Validates params, returns a *htm.ParamError naming the first invalid field
+func (p *ScalerEncoderParams) Validate() error {
+	if p.Width%2 == 0 {
+		return &htm.ParamError{Field: "Width", Reason: "must be an odd number"}
+	}
+	if p.MinVal >= p.MaxVal {
+		return &htm.ParamError{Field: "MinVal", Reason: "must be less than MaxVal"}
+	}
+
+	if p.N != 0 {
+		if p.Radius != 0 {
+			return &htm.ParamError{Field: "Radius", Reason: "must be 0 when N is set"}
+		}
+		if p.Resolution != 0 {
+			return &htm.ParamError{Field: "Resolution", Reason: "must be 0 when N is set"}
+		}
+		if p.N <= p.Width {
+			return &htm.ParamError{Field: "N", Reason: "must be greater than Width"}
+		}
+	} else if p.Radius != 0 {
+		if p.Resolution != 0 {
+			return &htm.ParamError{Field: "Resolution", Reason: "must be 0 when Radius is set"}
+		}
+	} else if p.Resolution == 0 {
+		return &htm.ParamError{Field: "N", Reason: "one of N, Radius, Resolution must be set"}
+	}
+
+	return nil
+}
+
 func NewScalerEncoder(p *ScalerEncoderParams) *ScalerEncoder {
+	if err := p.Validate(); err != nil {
+		panic(err)
+	}
+
 	se := new(ScalerEncoder)
 	se.ScalerEncoderParams = *p
 
-	if se.Width%2 == 0 {
-		panic("Width must be an odd number.")
-	}
-
 	se.halfWidth = (se.Width - 1) / 2
 
 	/* For non-periodic inputs, padding is the number of bits "outside" the range,
@@ -104,10 +134,6 @@ func NewScalerEncoder(p *ScalerEncoderParams) *ScalerEncoder {
 		se.padding = se.halfWidth
 	}
 
-	if se.MinVal >= se.MaxVal {
-		panic("MinVal must be less than MaxVal")
-	}
-
 	se.rangeInternal = se.MaxVal - se.MinVal
 
 	// There are three different ways of thinking about the representation. Handle
@@ -139,17 +165,6 @@ func (se *ScalerEncoder) initEncoder(width int, minval float64, maxval float64,
 	//handle 3 diff ways of representation
 
 	if n != 0 {
-		//crutches ;(
-		if radius != 0 {
-			panic("radius is not 0")
-		}
-		if resolution != 0 {
-			panic("resolution is not 0")
-		}
-		if n <= width {
-			panic("n less than width")
-		}
-
 		se.N = n
 
 		//if (minval is not None and maxval is not None){
@@ -170,16 +185,11 @@ func (se *ScalerEncoder) initEncoder(width int, minval float64, maxval float64,
 
 	} else { //n == 0
 		if radius != 0 {
-			if resolution != 0 {
-				panic("resolution not 0")
-			}
 			se.Radius = radius
 			se.Resolution = se.Radius / float64(width)
 		} else if resolution != 0 {
 			se.Resolution = resolution
 			se.Radius = se.Resolution * float64(se.Width)
-		} else {
-			panic("One of n, radius, resolution must be set")
 		}
 
 		if se.Periodic {
@@ -239,25 +249,25 @@ func (se *ScalerEncoder) getFirstOnBit(input float64) int {
 		} else {
 			panic(fmt.Sprintf("Input %v less than range %v - %v", input, se.MinVal, se.MaxVal))
 		}
+	}
 
-		if se.Periodic {
+	if se.Periodic {
 
-			// Don't clip periodic inputs. Out-of-range input is always an error
-			if input >= se.MaxVal {
-				panic(fmt.Sprintf("input %v greater than periodic range %v - %v", input, se.MinVal, se.MaxVal))
-			}
+		// Don't clip periodic inputs. Out-of-range input is always an error
+		if input >= se.MaxVal {
+			panic(fmt.Sprintf("input %v greater than periodic range %v - %v", input, se.MinVal, se.MaxVal))
+		}
 
-		} else {
+	} else {
 
-			if input > se.MaxVal {
-				if se.ClipInput {
-					if se.Verbosity > 0 {
-						fmt.Printf("Clipped input %v=%v to maxval %v", se.Name, input, se.MaxVal)
-					}
-					input = se.MaxVal
-				} else {
-					panic(fmt.Sprintf("input %v greater than range (%v - %v)", input, se.MinVal, se.MaxVal))
+		if input > se.MaxVal {
+			if se.ClipInput {
+				if se.Verbosity > 0 {
+					fmt.Printf("Clipped input %v=%v to maxval %v", se.Name, input, se.MaxVal)
 				}
+				input = se.MaxVal
+			} else {
+				panic(fmt.Sprintf("input %v greater than range (%v - %v)", input, se.MinVal, se.MaxVal))
 			}
 		}
 	}
@@ -378,22 +388,18 @@ func (se *ScalerEncoder) getTopDownMapping() *htm.SparseBinaryMatrix {
 	}
 
 	// The input scalar value corresponding to each possible output encoding
+	//Number of values is (max-min)/resolution
+	numValues := int(math.Ceil((se.MaxVal-se.MinVal)/se.Resolution)) + 1
+	se.topDownValues = make([]float64, 0, numValues)
 	if se.Periodic {
-		se.topDownValues = make([]float64, 0, int(se.MaxVal-se.MinVal))
 		start := se.MinVal + se.Resolution/2.0
-		idx := 0
 		for i := start; i <= se.MaxVal; i += se.Resolution {
-			se.topDownValues[idx] = i
-			idx++
+			se.topDownValues = append(se.topDownValues, i)
 		}
 	} else {
-		//Number of values is (max-min)/resolution
-		se.topDownValues = make([]float64, int(math.Ceil((se.MaxVal-se.MinVal)/se.Resolution)))
 		end := se.MaxVal + se.Resolution/2.0
-		idx := 0
 		for i := se.MinVal; i <= end; i += se.Resolution {
-			se.topDownValues[idx] = i
-			idx++
+			se.topDownValues = append(se.topDownValues, i)
 		}
 	}
 
@@ -659,3 +665,101 @@ func (se *ScalerEncoder) Decode(encoded []bool) []utils.TupleFloat {
 
 	return ranges
 }
+
+//Returns the number of bits in the output
+func (se *ScalerEncoder) OutputWidth() int {
+	return se.N
+}
+
+/*
+	Converts value to a float64 input, returns an error if value is not
+numeric or is outside the range the encoder accepts: periodic encoders
+accept [MinVal, MaxVal), other encoders [MinVal, MaxVal] unless they clip
+their input.
+*/
+func (se *ScalerEncoder) inputValue(value interface{}) (float64, error) {
+	input, err := toFloat64(value)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(input) {
+		return 0, fmt.Errorf("input %v outside range %v - %v", input, se.MinVal, se.MaxVal)
+	}
+	if se.Periodic {
+		if input < se.MinVal || input >= se.MaxVal {
+			return 0, fmt.Errorf("input %v outside periodic range %v - %v", input, se.MinVal, se.MaxVal)
+		}
+	} else if !se.ClipInput && (input < se.MinVal || input > se.MaxVal) {
+		return 0, fmt.Errorf("input %v outside range %v - %v", input, se.MinVal, se.MaxVal)
+	}
+	return input, nil
+}
+
+/*
+	Encodes a numeric value to specified slice, returns an error if value
+is not numeric or outside the range of the encoder
+*/
+func (se *ScalerEncoder) EncodeValueToSlice(value interface{}, learn bool, output []bool) error {
+	input, err := se.inputValue(value)
+	if err != nil {
+		return err
+	}
+	utils.FillSliceBool(output[:se.N], false)
+	se.EncodeToSlice(input, learn, output)
+	return nil
+}
+
+//Decodes encoded, returns the decoded ranges
+func (se *ScalerEncoder) DecodeValue(encoded []bool) interface{} {
+	return se.Decode(encoded)
+}
+
+//Returns the name and bit range of the output
+func (se *ScalerEncoder) Description() []EncoderField {
+	return []EncoderField{{Name: se.Name, Offset: 0, Width: se.N}}
+}
+
+//Returns the bucket index of a numeric value, returns an error if value
+//is not numeric or outside the range of the encoder
+func (se *ScalerEncoder) BucketIndices(value interface{}) ([]int, error) {
+	input, err := se.inputValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return se.getBucketIndices(input), nil
+}
+
+//Returns value and encoding of the specified bucket
+func (se *ScalerEncoder) BucketInfo(buckets []int) []EncoderResult {
+	value, encoding := se.getBucketInfo(buckets)
+	return []EncoderResult{{Value: value, Scalar: value, Encoding: encoding}}
+}
+
+/*
+	Returns 1 minus the error between expected and actual as a fraction
+of the encoder range. Periodic encoders measure the error the short way
+around.
+*/
+func (se *ScalerEncoder) Closeness(expected interface{}, actual interface{}) ([]float64, error) {
+	expVal, err := toFloat64(expected)
+	if err != nil {
+		return nil, err
+	}
+	actVal, err := toFloat64(actual)
+	if err != nil {
+		return nil, err
+	}
+
+	if se.Periodic {
+		expVal = math.Mod(expVal, se.MaxVal)
+		actVal = math.Mod(actVal, se.MaxVal)
+	}
+
+	diff := math.Abs(expVal - actVal)
+	if se.Periodic {
+		diff = math.Min(diff, se.MaxVal-diff)
+	}
+
+	pctErr := math.Min(1.0, diff/(se.MaxVal-se.MinVal))
+	return []float64{1.0 - pctErr}, nil
+}
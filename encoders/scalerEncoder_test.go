@@ -1,6 +1,7 @@
 package encoders
 
 import (
+	"github.com/nupic-community/htm"
 	"github.com/nupic-community/htm/utils"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -108,3 +109,65 @@ func TestSimpleDecoding(t *testing.T) {
 	assert.Equal(t, expected, actual)
 
 }
+
+func TestScalerEncoderParamsValidate(t *testing.T) {
+	p := NewScalerEncoderParams(3, 1, 8)
+	p.N = 14
+	assert.Nil(t, p.Validate())
+
+	p.Width = 4
+	err, ok := p.Validate().(*htm.ParamError)
+	assert.True(t, ok)
+	assert.Equal(t, "Width", err.Field)
+
+	p = NewScalerEncoderParams(3, 8, 1)
+	p.N = 14
+	err, ok = p.Validate().(*htm.ParamError)
+	assert.True(t, ok)
+	assert.Equal(t, "MinVal", err.Field)
+
+	p = NewScalerEncoderParams(3, 1, 8)
+	p.N = 14
+	p.Radius = 1
+	err, ok = p.Validate().(*htm.ParamError)
+	assert.True(t, ok)
+	assert.Equal(t, "Radius", err.Field)
+
+	p = NewScalerEncoderParams(3, 1, 8)
+	err, ok = p.Validate().(*htm.ParamError)
+	assert.True(t, ok)
+	assert.Equal(t, "N", err.Field)
+}
+
+func TestTopDownMappingResolution(t *testing.T) {
+	p := NewScalerEncoderParams(3, 0, 10)
+	p.Resolution = 0.5
+	e := NewScalerEncoder(p)
+
+	//one bucket for every resolution step including both ends
+	assert.Equal(t, 21, e.getTopDownMapping().Height)
+	value, encoding := e.getBucketInfo([]int{20})
+	assert.Equal(t, 10.0, value)
+	assert.Equal(t, e.Encode(10, false), encoding)
+	assert.Equal(t, 7.5, e.topDownCompute(e.Encode(7.5, false)))
+
+	p = NewScalerEncoderParams(3, 0, 8)
+	p.Resolution = 0.5
+	p.Periodic = true
+	e = NewScalerEncoder(p)
+
+	assert.Equal(t, 16, e.getTopDownMapping().Height)
+	value, _ = e.getBucketInfo([]int{15})
+	assert.Equal(t, 7.75, value)
+	assert.Equal(t, 2.25, e.topDownCompute(e.Encode(2.25, false)))
+}
+
+func TestClipInput(t *testing.T) {
+	p := NewScalerEncoderParams(3, 1, 8)
+	p.N = 14
+	p.ClipInput = true
+	e := NewScalerEncoder(p)
+
+	assert.Equal(t, e.Encode(1, false), e.Encode(-5, false))
+	assert.Equal(t, e.Encode(8, false), e.Encode(20, false))
+}
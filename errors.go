@@ -0,0 +1,16 @@
+package htm
+
+import (
+	"fmt"
+)
+
+//Error returned when a parameter or argument is invalid, Field names
+//the offending parameter
+type ParamError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("invalid %v: %v", e.Field, e.Reason)
+}
@@ -0,0 +1,78 @@
+package network
+
+import (
+	"fmt"
+	"github.com/nupic-community/htm"
+	"github.com/nupic-community/htm/utils"
+)
+
+/*
+	Region wrapping an SDR classifier. Reads the active cells from
+"activeCells" and classifies them against the bucket and value of the
+last record encoded by Source. It has no outputs, predictions are read
+with Result.
+*/
+type ClassifierRegion struct {
+	regionInputs
+	Classifier *htm.SDRClassifier
+	//Encoder whose bucket and value are learned, the first bucket is used
+	Source *EncoderRegion
+	Learn  bool
+	Infer  bool
+
+	recordNum int
+	result    *htm.ClassifierResult
+}
+
+/*
+	Intializes a new classifier region
+*/
+func NewClassifierRegion(classifier *htm.SDRClassifier, source *EncoderRegion) *ClassifierRegion {
+	cr := new(ClassifierRegion)
+	cr.regionInputs = newRegionInputs("activeCells")
+	cr.Classifier = classifier
+	cr.Source = source
+	cr.Learn = true
+	cr.Infer = true
+	return cr
+}
+
+func (cr *ClassifierRegion) Outputs() []string {
+	return nil
+}
+
+func (cr *ClassifierRegion) Output(name string) []bool {
+	return nil
+}
+
+//Returns the inference result of the last compute, nil if Infer is off
+func (cr *ClassifierRegion) Result() *htm.ClassifierResult {
+	return cr.result
+}
+
+func (cr *ClassifierRegion) Compute() error {
+	input, err := cr.input("activeCells")
+	if err != nil {
+		return err
+	}
+
+	bucketIdx := -1
+	var value interface{}
+	if cr.Source != nil {
+		buckets := cr.Source.BucketIndices()
+		if len(buckets) == 0 {
+			return fmt.Errorf("source has not encoded a value")
+		}
+		bucketIdx = buckets[0]
+		value = cr.Source.Value()
+	}
+
+	cr.result = cr.Classifier.Compute(cr.recordNum, utils.OnIndices(input), bucketIdx,
+		value, cr.Learn, cr.Infer)
+	cr.recordNum++
+	return nil
+}
+
+//The classifier learns across sequence boundaries
+func (cr *ClassifierRegion) Reset() {
+}
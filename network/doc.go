@@ -0,0 +1,5 @@
+/*
+network links encoders, poolers, temporal memory and classifiers into a
+network of regions that is computed as a whole each step.
+*/
+package network
@@ -0,0 +1,89 @@
+package network
+
+import (
+	"fmt"
+	"github.com/nupic-community/htm/encoders"
+)
+
+/*
+	Region that encodes values fed to it with SetValue. It has no inputs
+and a single "encoded" output.
+*/
+type EncoderRegion struct {
+	Encoder encoders.Encoder
+	Learn   bool
+
+	value    interface{}
+	hasValue bool
+	output   []bool
+	buckets  []int
+}
+
+/*
+	Intializes a new encoder region
+*/
+func NewEncoderRegion(encoder encoders.Encoder) *EncoderRegion {
+	er := new(EncoderRegion)
+	er.Encoder = encoder
+	er.Learn = true
+	er.output = make([]bool, encoder.OutputWidth())
+	return er
+}
+
+//Sets the value encoded by the next compute
+func (er *EncoderRegion) SetValue(value interface{}) {
+	er.value = value
+	er.hasValue = true
+}
+
+//Returns the value encoded by the last compute
+func (er *EncoderRegion) Value() interface{} {
+	return er.value
+}
+
+//Returns the encoder buckets of the value encoded by the last compute
+func (er *EncoderRegion) BucketIndices() []int {
+	return er.buckets
+}
+
+func (er *EncoderRegion) Inputs() []string {
+	return nil
+}
+
+func (er *EncoderRegion) Outputs() []string {
+	return []string{"encoded"}
+}
+
+func (er *EncoderRegion) SetInput(name string, value []bool) error {
+	return fmt.Errorf("unknown input %v", name)
+}
+
+func (er *EncoderRegion) Output(name string) []bool {
+	if name == "encoded" {
+		return er.output
+	}
+	return nil
+}
+
+func (er *EncoderRegion) Compute() error {
+	if !er.hasValue {
+		return fmt.Errorf("no value set")
+	}
+
+	output := make([]bool, er.Encoder.OutputWidth())
+	if err := er.Encoder.EncodeValueToSlice(er.value, er.Learn, output); err != nil {
+		return err
+	}
+	buckets, err := er.Encoder.BucketIndices(er.value)
+	if err != nil {
+		return err
+	}
+
+	er.output = output
+	er.buckets = buckets
+	return nil
+}
+
+//Encoders have no sequence state
+func (er *EncoderRegion) Reset() {
+}
@@ -0,0 +1,194 @@
+package network
+
+import (
+	"fmt"
+	"github.com/nupic-community/htm"
+	"github.com/nupic-community/htm/utils"
+)
+
+/*
+	Params for one level of a hierarchy
+
+Output -- Temporal memory output fed to the next level, "activeCells" or
+"predictedActiveCells".
+
+PoolingWindow -- Number of steps the output is pooled over before it is fed
+to the next level, 0 disables temporal pooling.
+
+LearnSP, LearnTM -- Initial learning switches of the level.
+
+The InputDimensions of every level but the first, and the ColumnDimensions
+of every temporal memory, are wired automatically.
+*/
+type LevelParams struct {
+	SpParams      htm.SpParams
+	TmParams      htm.TemporalMemoryParams
+	Output        string
+	PoolingWindow int
+	LearnSP       bool
+	LearnTM       bool
+}
+
+//Create default level params
+func NewLevelParams() *LevelParams {
+	p := new(LevelParams)
+
+	p.SpParams = htm.NewSpParams()
+	p.TmParams = *htm.NewTemporalMemoryParams()
+	p.Output = "activeCells"
+	p.PoolingWindow = 0
+	p.LearnSP = true
+	p.LearnTM = true
+
+	return p
+}
+
+//Validates params, returns a *htm.ParamError naming the first invalid field
+func (p *LevelParams) Validate() error {
+	if p.Output != "activeCells" && p.Output != "predictedActiveCells" {
+		return &htm.ParamError{Field: "Output", Reason: "must be activeCells or predictedActiveCells"}
+	}
+	if p.PoolingWindow < 0 {
+		return &htm.ParamError{Field: "PoolingWindow", Reason: "must not be negative"}
+	}
+	if err := p.SpParams.Validate(); err != nil {
+		return err
+	}
+	return p.TmParams.Validate()
+}
+
+/*
+	A level of a hierarchy, a spatial pooler feeding a temporal memory
+and an optional union pooler feeding the next level
+*/
+type Level struct {
+	SpatialPooler  *SpatialPoolerRegion
+	TemporalMemory *TemporalMemoryRegion
+	//nil when pooling is disabled
+	Pooler *UnionPoolerRegion
+
+	output string
+}
+
+//Sets the learning switches of the level
+func (l *Level) SetLearning(learnSP bool, learnTM bool) {
+	l.SpatialPooler.Learn = learnSP
+	l.TemporalMemory.Learn = learnTM
+}
+
+//Returns the cells the level feeds to the next level
+func (l *Level) Output() []bool {
+	if l.Pooler != nil {
+		return l.Pooler.Output("bottomUpOut")
+	}
+	return l.TemporalMemory.Output(l.output)
+}
+
+/*
+ A hierarchy stacks spatial pooler / temporal memory levels. The cells
+of each level's temporal memory are the input vector of the next level's
+spatial pooler. The levels are regions of a network named sp<level>,
+tm<level> and pool<level>, more regions can be linked to it.
+*/
+type Hierarchy struct {
+	Network *Network
+	Levels  []*Level
+}
+
+/*
+	Intializes a new hierarchy, params are copied and wired so each
+spatial pooler matches the cells of the level below it
+*/
+func NewHierarchy(params []*LevelParams) (*Hierarchy, error) {
+	if len(params) == 0 {
+		return nil, &htm.ParamError{Field: "params", Reason: "must contain at least 1 level"}
+	}
+
+	h := new(Hierarchy)
+	h.Network = NewNetwork()
+
+	var below *LevelParams
+	for idx, p := range params {
+		lp := *p
+		if below != nil {
+			numCells := utils.ProdInt(below.TmParams.ColumnDimensions) * below.TmParams.CellsPerColumn
+			lp.SpParams.InputDimensions = []int{numCells}
+		}
+		lp.TmParams.ColumnDimensions = lp.SpParams.ColumnDimensions
+
+		if err := lp.Validate(); err != nil {
+			return nil, fmt.Errorf("level %v: %v", idx, err)
+		}
+
+		level := new(Level)
+		level.output = lp.Output
+		level.SpatialPooler = NewSpatialPoolerRegion(htm.NewSpatialPooler(lp.SpParams))
+		tmParams := lp.TmParams
+		level.TemporalMemory = NewTemporalMemoryRegion(htm.NewTemporalMemory(&tmParams))
+		level.SetLearning(lp.LearnSP, lp.LearnTM)
+
+		spName := fmt.Sprintf("sp%v", idx)
+		tmName := fmt.Sprintf("tm%v", idx)
+		if err := h.Network.AddRegion(spName, level.SpatialPooler); err != nil {
+			return nil, fmt.Errorf("level %v: %v", idx, err)
+		}
+		if err := h.Network.AddRegion(tmName, level.TemporalMemory); err != nil {
+			return nil, fmt.Errorf("level %v: %v", idx, err)
+		}
+		if err := h.Network.Link(spName, "bottomUpOut", tmName, "bottomUpIn"); err != nil {
+			return nil, fmt.Errorf("level %v: %v", idx, err)
+		}
+
+		if lp.PoolingWindow > 0 {
+			numCells := level.TemporalMemory.TemporalMemory.Connections.NumberOfcells()
+			level.Pooler = NewUnionPoolerRegion(numCells, lp.PoolingWindow)
+			poolName := fmt.Sprintf("pool%v", idx)
+			if err := h.Network.AddRegion(poolName, level.Pooler); err != nil {
+				return nil, fmt.Errorf("level %v: %v", idx, err)
+			}
+			if err := h.Network.Link(tmName, lp.Output, poolName, "bottomUpIn"); err != nil {
+				return nil, fmt.Errorf("level %v: %v", idx, err)
+			}
+		}
+
+		if idx > 0 {
+			if err := h.linkLevels(idx-1, spName); err != nil {
+				return nil, fmt.Errorf("level %v: %v", idx, err)
+			}
+		}
+
+		h.Levels = append(h.Levels, level)
+		below = &lp
+	}
+
+	return h, nil
+}
+
+//Links the output of a level to the input of the next spatial pooler
+func (h *Hierarchy) linkLevels(level int, spName string) error {
+	below := h.Levels[level]
+	if below.Pooler != nil {
+		return h.Network.Link(fmt.Sprintf("pool%v", level), "bottomUpOut", spName, "bottomUpIn")
+	}
+	return h.Network.Link(fmt.Sprintf("tm%v", level), below.output, spName, "bottomUpIn")
+}
+
+//Returns the top level
+func (h *Hierarchy) Top() *Level {
+	return h.Levels[len(h.Levels)-1]
+}
+
+/*
+	Feeds an input vector to the first level and computes every level
+*/
+func (h *Hierarchy) Compute(input []bool) error {
+	if err := h.Levels[0].SpatialPooler.SetInput("bottomUpIn", input); err != nil {
+		return err
+	}
+	return h.Network.Compute()
+}
+
+//Indicates the start of a new sequence on every level
+func (h *Hierarchy) Reset() {
+	h.Network.Reset()
+}
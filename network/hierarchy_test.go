@@ -0,0 +1,132 @@
+package network
+
+import (
+	"github.com/nupic-community/htm"
+	"github.com/nupic-community/htm/utils"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func testLevelParams(columns int, cellsPerColumn int) *LevelParams {
+	p := NewLevelParams()
+	p.SpParams.ColumnDimensions = []int{columns}
+	p.SpParams.NumActiveColumnsPerInhArea = 4
+	p.SpParams.PotentialRadius = 1000
+	p.SpParams.GlobalInhibition = true
+	p.TmParams.CellsPerColumn = cellsPerColumn
+	return p
+}
+
+func TestHierarchyWiring(t *testing.T) {
+	l1 := testLevelParams(32, 4)
+	l1.SpParams.InputDimensions = []int{50}
+	l2 := testLevelParams(16, 2)
+	l2.SpParams.InputDimensions = []int{7}
+	l2.PoolingWindow = 3
+	l3 := testLevelParams(8, 2)
+	l3.LearnSP = false
+	l3.LearnTM = false
+
+	h, err := NewHierarchy([]*LevelParams{l1, l2, l3})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(h.Levels))
+
+	// Inputs are wired to the cells of the level below
+	assert.Equal(t, []int{128}, h.Levels[1].SpatialPooler.SpatialPooler.InputDimensions)
+	assert.Equal(t, []int{32}, h.Levels[2].SpatialPooler.SpatialPooler.InputDimensions)
+	assert.Equal(t, 32, h.Levels[1].TemporalMemory.TemporalMemory.Connections.NumberOfcells())
+	// Params are copied
+	assert.Equal(t, []int{7}, l2.SpParams.InputDimensions)
+
+	assert.Nil(t, h.Levels[0].Pooler)
+	assert.NotNil(t, h.Levels[1].Pooler)
+	assert.True(t, h.Levels[1].SpatialPooler.Learn)
+	assert.False(t, h.Top().SpatialPooler.Learn)
+	assert.False(t, h.Top().TemporalMemory.Learn)
+
+	order, err := h.Network.TopologicalOrder()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"sp0", "tm0", "sp1", "tm1", "pool1", "sp2", "tm2"}, order)
+
+	input := make([]bool, 50)
+	utils.FillSliceRangeBool(input, true, 10, 10)
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, h.Compute(input))
+	}
+	assert.Equal(t, 16, len(h.Top().Output()))
+
+	// Wrong input size
+	assert.NotNil(t, h.Compute(make([]bool, 10)))
+}
+
+func TestHierarchyInvalidParams(t *testing.T) {
+	_, err := NewHierarchy(nil)
+	assert.NotNil(t, err)
+
+	l1 := testLevelParams(32, 4)
+	l1.SpParams.InputDimensions = []int{50}
+	l2 := testLevelParams(16, 2)
+	l2.Output = "foo"
+	_, err = NewHierarchy([]*LevelParams{l1, l2})
+	assert.NotNil(t, err)
+}
+
+func TestHierarchyLinkErrors(t *testing.T) {
+	l1 := testLevelParams(32, 4)
+	l1.SpParams.InputDimensions = []int{50}
+	h, err := NewHierarchy([]*LevelParams{l1})
+	assert.Nil(t, err)
+	links := len(h.Network.Links())
+
+	// wiring failures are reported instead of leaving levels disconnected
+	assert.NotNil(t, h.linkLevels(0, "sp1"))
+	h.Levels[0].output = "foo"
+	assert.NotNil(t, h.linkLevels(0, "sp0"))
+	assert.Equal(t, links, len(h.Network.Links()))
+}
+
+func TestUnionPoolerRegion(t *testing.T) {
+	upr := NewUnionPoolerRegion(4, 2)
+	inputs := [][]bool{
+		{true, false, false, false},
+		{false, true, false, false},
+		{false, false, true, false},
+	}
+
+	upr.SetInput("bottomUpIn", inputs[0])
+	upr.Compute()
+	assert.Equal(t, []bool{true, false, false, false}, upr.Output("bottomUpOut"))
+	upr.SetInput("bottomUpIn", inputs[1])
+	upr.Compute()
+	assert.Equal(t, []bool{true, true, false, false}, upr.Output("bottomUpOut"))
+	upr.SetInput("bottomUpIn", inputs[2])
+	upr.Compute()
+	assert.Equal(t, []bool{false, true, true, false}, upr.Output("bottomUpOut"))
+
+	upr.Reset()
+	assert.Equal(t, []bool{false, false, false, false}, upr.Output("bottomUpOut"))
+}
+
+func TestPredictedActiveCells(t *testing.T) {
+	tmr := NewTemporalMemoryRegion(testTemporalMemory())
+	input := make([]bool, 8)
+	input[1] = true
+	input[2] = true
+
+	tmr.SetInput("bottomUpIn", input)
+	tmr.Compute()
+	assert.Equal(t, 0, utils.CountTrue(tmr.Output("predictedActiveCells")))
+
+	// cells 4-7 are in column 1
+	tmr.TemporalMemory.PredictiveCells = []int{5, 12}
+	tmr.predictiveCells = denseCells(32, tmr.TemporalMemory.PredictiveCells)
+	tmr.Compute()
+	assert.Equal(t, []int{5}, utils.OnIndices(tmr.Output("predictedActiveCells")))
+}
+
+func testTemporalMemory() *htm.TemporalMemory {
+	p := htm.NewTemporalMemoryParams()
+	p.ColumnDimensions = []int{8}
+	p.CellsPerColumn = 4
+	return htm.NewTemporalMemory(p)
+}
@@ -0,0 +1,194 @@
+package network
+
+import (
+	"fmt"
+)
+
+//Connects an output of one region to an input of another
+type Link struct {
+	SrcRegion  string
+	SrcOutput  string
+	DestRegion string
+	DestInput  string
+}
+
+/*
+ A network of named regions connected by links. Each call to Compute
+computes every region once in topological order, before a region is
+computed its linked inputs are set from the outputs of its sources. If
+several links feed the same input their outputs are concatenated in the
+order the links were added.
+*/
+type Network struct {
+	regions map[string]Region
+	//region names in the order they were added
+	names []string
+	links []Link
+	//cached topological order, nil when invalidated
+	order []string
+}
+
+/*
+	Intializes a new empty network
+*/
+func NewNetwork() *Network {
+	n := new(Network)
+	n.regions = make(map[string]Region)
+	return n
+}
+
+//Adds a region to the network
+func (n *Network) AddRegion(name string, region Region) error {
+	if _, ok := n.regions[name]; ok {
+		return fmt.Errorf("duplicate region %v", name)
+	}
+	n.regions[name] = region
+	n.names = append(n.names, name)
+	n.order = nil
+	return nil
+}
+
+//Returns the named region or nil
+func (n *Network) Region(name string) Region {
+	return n.regions[name]
+}
+
+//Returns the names of all regions in the order they were added
+func (n *Network) Regions() []string {
+	result := make([]string, len(n.names))
+	copy(result, n.names)
+	return result
+}
+
+//Returns all links in the order they were added
+func (n *Network) Links() []Link {
+	result := make([]Link, len(n.links))
+	copy(result, n.links)
+	return result
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+	Links an output of the source region to an input of the destination
+region
+*/
+func (n *Network) Link(srcRegion, srcOutput, destRegion, destInput string) error {
+	src, ok := n.regions[srcRegion]
+	if !ok {
+		return fmt.Errorf("unknown region %v", srcRegion)
+	}
+	dest, ok := n.regions[destRegion]
+	if !ok {
+		return fmt.Errorf("unknown region %v", destRegion)
+	}
+	if !contains(src.Outputs(), srcOutput) {
+		return fmt.Errorf("region %v has no output %v", srcRegion, srcOutput)
+	}
+	if !contains(dest.Inputs(), destInput) {
+		return fmt.Errorf("region %v has no input %v", destRegion, destInput)
+	}
+
+	n.links = append(n.links, Link{srcRegion, srcOutput, destRegion, destInput})
+	n.order = nil
+	return nil
+}
+
+/*
+	Returns the region names ordered so that every region comes after
+all regions linked to its inputs. Returns an error if the links contain
+a cycle.
+*/
+func (n *Network) TopologicalOrder() ([]string, error) {
+	if n.order != nil {
+		return n.order, nil
+	}
+
+	inDegree := make(map[string]int, len(n.names))
+	for _, link := range n.links {
+		inDegree[link.DestRegion]++
+	}
+
+	order := make([]string, 0, len(n.names))
+	done := make(map[string]bool, len(n.names))
+	for len(order) < len(n.names) {
+		progress := false
+		// Pick ready regions in insertion order to keep the order stable
+		for _, name := range n.names {
+			if done[name] || inDegree[name] > 0 {
+				continue
+			}
+			done[name] = true
+			order = append(order, name)
+			progress = true
+			for _, link := range n.links {
+				if link.SrcRegion == name {
+					inDegree[link.DestRegion]--
+				}
+			}
+		}
+		if !progress {
+			return nil, fmt.Errorf("network links contain a cycle")
+		}
+	}
+
+	n.order = order
+	return order, nil
+}
+
+//Sets the linked inputs of a region from the outputs of its sources
+func (n *Network) propagate(name string) error {
+	inputs := make(map[string][]bool)
+	var inputNames []string
+	for _, link := range n.links {
+		if link.DestRegion != name {
+			continue
+		}
+		if _, ok := inputs[link.DestInput]; !ok {
+			inputNames = append(inputNames, link.DestInput)
+		}
+		output := n.regions[link.SrcRegion].Output(link.SrcOutput)
+		inputs[link.DestInput] = append(inputs[link.DestInput], output...)
+	}
+
+	for _, input := range inputNames {
+		if err := n.regions[name].SetInput(input, inputs[input]); err != nil {
+			return fmt.Errorf("region %v: %v", name, err)
+		}
+	}
+	return nil
+}
+
+/*
+	Computes every region once in topological order
+*/
+func (n *Network) Compute() error {
+	order, err := n.TopologicalOrder()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range order {
+		if err := n.propagate(name); err != nil {
+			return err
+		}
+		if err := n.regions[name].Compute(); err != nil {
+			return fmt.Errorf("region %v: %v", name, err)
+		}
+	}
+	return nil
+}
+
+//Resets the sequence state of every region
+func (n *Network) Reset() {
+	for _, name := range n.names {
+		n.regions[name].Reset()
+	}
+}
@@ -0,0 +1,165 @@
+package network
+
+import (
+	"github.com/nupic-community/htm"
+	"github.com/nupic-community/htm/encoders"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+//Region that records its inputs and outputs a fixed value
+type testRegion struct {
+	regionInputs
+	value    []bool
+	computed int
+	resets   int
+	last     []bool
+}
+
+func newTestRegion(value []bool, inputs ...string) *testRegion {
+	tr := new(testRegion)
+	tr.regionInputs = newRegionInputs(inputs...)
+	tr.value = value
+	return tr
+}
+
+func (tr *testRegion) Outputs() []string {
+	return []string{"out"}
+}
+
+func (tr *testRegion) Output(name string) []bool {
+	return tr.value
+}
+
+func (tr *testRegion) Compute() error {
+	tr.computed++
+	if len(tr.names) > 0 {
+		input, err := tr.input(tr.names[0])
+		if err != nil {
+			return err
+		}
+		tr.last = input
+	}
+	return nil
+}
+
+func (tr *testRegion) Reset() {
+	tr.resets++
+}
+
+func TestTopologicalOrder(t *testing.T) {
+	n := NewNetwork()
+	assert.Nil(t, n.AddRegion("c", newTestRegion(nil, "in")))
+	assert.Nil(t, n.AddRegion("b", newTestRegion(nil, "in")))
+	assert.Nil(t, n.AddRegion("a", newTestRegion(nil)))
+	assert.NotNil(t, n.AddRegion("a", newTestRegion(nil)))
+
+	assert.Nil(t, n.Link("b", "out", "c", "in"))
+	assert.Nil(t, n.Link("a", "out", "b", "in"))
+
+	order, err := n.TopologicalOrder()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, order)
+
+	// Cycle
+	assert.Nil(t, n.Link("c", "out", "b", "in"))
+	_, err = n.TopologicalOrder()
+	assert.NotNil(t, err)
+	assert.NotNil(t, n.Compute())
+}
+
+func TestLinkErrors(t *testing.T) {
+	n := NewNetwork()
+	n.AddRegion("a", newTestRegion(nil))
+	n.AddRegion("b", newTestRegion(nil, "in"))
+
+	assert.NotNil(t, n.Link("x", "out", "b", "in"))
+	assert.NotNil(t, n.Link("a", "out", "x", "in"))
+	assert.NotNil(t, n.Link("a", "foo", "b", "in"))
+	assert.NotNil(t, n.Link("a", "out", "b", "foo"))
+	assert.Equal(t, 0, len(n.Links()))
+}
+
+func TestComputeConcatenatesInputs(t *testing.T) {
+	n := NewNetwork()
+	a := newTestRegion([]bool{true, false})
+	b := newTestRegion([]bool{false, false, true})
+	c := newTestRegion(nil, "in")
+	n.AddRegion("c", c)
+	n.AddRegion("a", a)
+	n.AddRegion("b", b)
+	n.Link("b", "out", "c", "in")
+	n.Link("a", "out", "c", "in")
+
+	assert.Nil(t, n.Compute())
+	assert.Equal(t, []bool{false, false, true, true, false}, c.last)
+	assert.Equal(t, 1, a.computed)
+	assert.Equal(t, 1, c.computed)
+
+	n.Reset()
+	assert.Equal(t, 1, a.resets)
+	assert.Equal(t, 1, c.resets)
+}
+
+func TestComputeUnlinkedInput(t *testing.T) {
+	n := NewNetwork()
+	n.AddRegion("a", newTestRegion(nil, "in"))
+	assert.NotNil(t, n.Compute())
+}
+
+func TestNetworkPipeline(t *testing.T) {
+	er := newTestEncoderRegion()
+	encoder := er.Encoder
+
+	spParams := htm.NewSpParams()
+	spParams.InputDimensions = encoders.InputDimensions(encoder)
+	spParams.ColumnDimensions = []int{64}
+	spParams.NumActiveColumnsPerInhArea = 4
+	spParams.GlobalInhibition = true
+	sp := htm.NewSpatialPooler(spParams)
+
+	tmParams := htm.NewTemporalMemoryParams()
+	tmParams.ColumnDimensions = spParams.ColumnDimensions
+	tmParams.CellsPerColumn = 4
+	tm := htm.NewTemporalMemory(tmParams)
+
+	spr := NewSpatialPoolerRegion(sp)
+	tmr := NewTemporalMemoryRegion(tm)
+	cr := NewClassifierRegion(htm.NewSDRClassifier(htm.NewSDRClassifierParams()), er)
+
+	n := NewNetwork()
+	assert.Nil(t, n.AddRegion("classifier", cr))
+	assert.Nil(t, n.AddRegion("tm", tmr))
+	assert.Nil(t, n.AddRegion("sp", spr))
+	assert.Nil(t, n.AddRegion("encoder", er))
+	assert.Nil(t, n.Link("encoder", "encoded", "sp", "bottomUpIn"))
+	assert.Nil(t, n.Link("sp", "bottomUpOut", "tm", "bottomUpIn"))
+	assert.Nil(t, n.Link("tm", "activeCells", "classifier", "activeCells"))
+
+	order, err := n.TopologicalOrder()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"encoder", "sp", "tm", "classifier"}, order)
+
+	// Compute without a value fails
+	assert.NotNil(t, n.Compute())
+
+	for i := 0; i < 10; i++ {
+		er.SetValue(float64(i % 5))
+		assert.Nil(t, n.Compute())
+	}
+
+	assert.Equal(t, 50, len(er.Output("encoded")))
+	assert.Equal(t, 64, len(spr.Output("bottomUpOut")))
+	assert.Equal(t, 256, len(tmr.Output("activeCells")))
+	assert.NotNil(t, cr.Result())
+	assert.Equal(t, 4.0, er.Value())
+
+	n.Reset()
+	assert.Equal(t, 0, len(tm.ActiveCells))
+}
+
+func newTestEncoderRegion() *EncoderRegion {
+	encParams := encoders.NewScalerEncoderParams(5, 0, 10)
+	encParams.N = 50
+	return NewEncoderRegion(encoders.NewScalerEncoder(encParams))
+}
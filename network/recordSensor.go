@@ -0,0 +1,317 @@
+package network
+
+import (
+	"fmt"
+	"github.com/nupic-community/htm"
+	"github.com/nupic-community/htm/encoders"
+	"time"
+)
+
+//Value range of a numeric field
+type ValueRange struct {
+	Min float64
+	Max float64
+}
+
+/*
+	Params for the record sensor
+
+ScalarWidth, ScalarN -- Width and output size of the scaler encoders of
+float and int fields. Values outside the field range are clipped.
+
+CategoryWidth -- Bits per category of string and bool fields.
+
+Encoders -- Encoders used instead of the defaults, keyed by field name.
+
+FieldRanges -- Value range of float and int fields, keyed by field name.
+
+Categories -- Known categories of string fields, keyed by field name.
+
+MaxCategories -- Category slots of string fields. Categories first seen
+while streaming are learned until the slots are used up, 0 reserves only
+the known categories.
+
+PrescanRecords -- Number of records read ahead to derive the range and
+categories of fields missing from FieldRanges and Categories. -1 scans the
+whole stream, which leaks future values into the encoders.
+*/
+type RecordSensorParams struct {
+	ScalarWidth    int
+	ScalarN        int
+	CategoryWidth  int
+	Encoders       map[string]encoders.Encoder
+	FieldRanges    map[string]ValueRange
+	Categories     map[string][]string
+	MaxCategories  int
+	PrescanRecords int
+}
+
+//Create default record sensor params
+func NewRecordSensorParams() *RecordSensorParams {
+	p := new(RecordSensorParams)
+
+	p.ScalarWidth = 21
+	p.ScalarN = 200
+	p.CategoryWidth = 21
+	p.Encoders = make(map[string]encoders.Encoder)
+	p.FieldRanges = make(map[string]ValueRange)
+	p.Categories = make(map[string][]string)
+	p.MaxCategories = 0
+	p.PrescanRecords = 100
+
+	return p
+}
+
+//Validates params, returns a *htm.ParamError naming the first invalid field
+func (p *RecordSensorParams) Validate() error {
+	for name, r := range p.FieldRanges {
+		if r.Min >= r.Max {
+			return &htm.ParamError{Field: "FieldRanges", Reason: fmt.Sprintf("min of %v must be less than max", name)}
+		}
+	}
+	if p.MaxCategories < 0 {
+		return &htm.ParamError{Field: "MaxCategories", Reason: "must not be negative"}
+	}
+	if p.PrescanRecords < -1 {
+		return &htm.ParamError{Field: "PrescanRecords", Reason: "must be -1 or greater"}
+	}
+	return nil
+}
+
+//Anything that holds sequence state, eg a temporal pooler or memory
+type Resetter interface {
+	Reset()
+}
+
+/*
+ A record sensor is a region that reads one record of a record stream
+each compute and encodes it to its "encoded" output. Every field except
+reset and sequence fields is encoded by a multi encoder: float and int
+fields with a scaler encoder, datetime fields with a date encoder and
+string and bool fields with a category encoder. When a record starts a
+new sequence the reset targets are reset before downstream regions
+compute it.
+*/
+type RecordSensor struct {
+	Stream  *RecordStream
+	Encoder *encoders.MultiEncoder
+	//Reset at the start of every sequence
+	ResetTargets []Resetter
+
+	fields       []FieldMeta
+	record       map[string]interface{}
+	reset        bool
+	lastSequence interface{}
+	output       []bool
+}
+
+/*
+	Intializes a new record sensor, creating an encoder for every
+encoded field of the stream. Records are read ahead as configured by
+PrescanRecords, they are still returned by the stream afterwards.
+*/
+func NewRecordSensor(stream *RecordStream, params *RecordSensorParams) (*RecordSensor, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	if params.PrescanRecords != 0 {
+		if _, err := stream.Prescan(params.PrescanRecords); err != nil {
+			return nil, err
+		}
+	}
+
+	rs := new(RecordSensor)
+	rs.Stream = stream
+	rs.Encoder = encoders.NewMultiEncoder("record")
+
+	for _, field := range stream.Fields() {
+		if field.Flag == ResetFlag || field.Flag == SequenceFlag {
+			continue
+		}
+		encoder, ok := params.Encoders[field.Name]
+		if !ok {
+			var err error
+			encoder, err = defaultFieldEncoder(stream, field, params)
+			if err != nil {
+				return nil, fmt.Errorf("field %v: %v", field.Name, err)
+			}
+		}
+		if err := rs.Encoder.AddEncoder(field.Name, encoder); err != nil {
+			return nil, err
+		}
+		rs.fields = append(rs.fields, field)
+	}
+
+	rs.output = make([]bool, rs.Encoder.OutputWidth())
+	return rs, nil
+}
+
+//Creates the encoder for the type of a field
+func defaultFieldEncoder(stream *RecordStream, field FieldMeta, params *RecordSensorParams) (encoders.Encoder, error) {
+	switch field.Type {
+	case FloatField, IntField:
+		r, ok := params.FieldRanges[field.Name]
+		if !ok {
+			r.Min, r.Max, ok = stream.FieldRange(field.Name)
+			if !ok {
+				return nil, &htm.ParamError{Field: "FieldRanges",
+					Reason: fmt.Sprintf("no range for %v, set it or prescan records", field.Name)}
+			}
+			if r.Min == r.Max {
+				r.Max = r.Min + 1
+			}
+		}
+		p := encoders.NewScalerEncoderParams(params.ScalarWidth, r.Min, r.Max)
+		p.N = params.ScalarN
+		p.ClipInput = true
+		p.Name = field.Name
+		if err := p.Validate(); err != nil {
+			return nil, err
+		}
+		return encoders.NewScalerEncoder(p), nil
+	case DateTimeField:
+		p := encoders.NewDateEncoderParams()
+		p.Name = field.Name
+		return encoders.NewDateEncoder(p), nil
+	case StringField, BoolField:
+		categories := []string{"false", "true"}
+		if field.Type == StringField {
+			var ok bool
+			if categories, ok = params.Categories[field.Name]; !ok {
+				categories = stream.Categories(field.Name)
+			}
+		}
+		p := encoders.NewCategoryEncoderParams(params.CategoryWidth, categories)
+		p.Name = field.Name
+		if field.Type == StringField && params.MaxCategories > len(categories) {
+			p.MaxCategories = params.MaxCategories
+			p.LearnCategories = true
+		}
+		if p.MaxCategories == 0 {
+			p.MaxCategories = 1
+		}
+		if err := p.Validate(); err != nil {
+			return nil, err
+		}
+		return encoders.NewCategoryEncoder(p), nil
+	}
+	return nil, &htm.ParamError{Field: "Type", Reason: fmt.Sprintf("unsupported field type %v", field.Type)}
+}
+
+//Adds a region or model that is reset at the start of every sequence
+func (rs *RecordSensor) AddResetTarget(target Resetter) {
+	rs.ResetTargets = append(rs.ResetTargets, target)
+}
+
+//Returns the last record read
+func (rs *RecordSensor) Record() map[string]interface{} {
+	return rs.record
+}
+
+//Returns true if the last record started a new sequence
+func (rs *RecordSensor) IsReset() bool {
+	return rs.reset
+}
+
+func (rs *RecordSensor) Inputs() []string {
+	return nil
+}
+
+func (rs *RecordSensor) Outputs() []string {
+	return []string{"encoded"}
+}
+
+func (rs *RecordSensor) SetInput(name string, value []bool) error {
+	return fmt.Errorf("unknown input %v", name)
+}
+
+func (rs *RecordSensor) Output(name string) []bool {
+	if name == "encoded" {
+		return rs.output
+	}
+	return nil
+}
+
+/*
+	Reads and encodes the next record, returns io.EOF at the end of the
+stream
+*/
+func (rs *RecordSensor) Compute() error {
+	record, err := rs.Stream.Next()
+	if err != nil {
+		return err
+	}
+
+	rs.reset = rs.startsSequence(record)
+	if rs.reset {
+		for _, target := range rs.ResetTargets {
+			target.Reset()
+		}
+	}
+
+	values := make(map[string]interface{}, len(rs.fields))
+	for _, field := range rs.fields {
+		values[field.Name] = encoderValue(field, record[field.Name])
+	}
+
+	output := make([]bool, rs.Encoder.OutputWidth())
+	if err := rs.Encoder.EncodeToSlice(values, true, output); err != nil {
+		return err
+	}
+
+	rs.record = record
+	rs.output = output
+	return nil
+}
+
+//Returns true if record is flagged as reset or changes the sequence id
+func (rs *RecordSensor) startsSequence(record map[string]interface{}) bool {
+	result := false
+	for _, field := range rs.Stream.fields {
+		value := record[field.Name]
+		switch field.Flag {
+		case ResetFlag:
+			switch v := value.(type) {
+			case bool:
+				result = result || v
+			case int:
+				result = result || v != 0
+			case float64:
+				result = result || v != 0
+			case string:
+				result = result || (len(v) != 0 && v != "0")
+			}
+		case SequenceFlag:
+			if rs.lastSequence != nil && !sameValue(rs.lastSequence, value) {
+				result = true
+			}
+			rs.lastSequence = value
+		}
+	}
+	return result
+}
+
+func sameValue(a, b interface{}) bool {
+	if ta, ok := a.(time.Time); ok {
+		tb, ok := b.(time.Time)
+		return ok && ta.Equal(tb)
+	}
+	return a == b
+}
+
+//Converts a parsed value to the value type expected by the field encoder
+func encoderValue(field FieldMeta, value interface{}) interface{} {
+	if v, ok := value.(bool); ok && field.Type == BoolField {
+		if v {
+			return "true"
+		}
+		return "false"
+	}
+	return value
+}
+
+//Restarts sequence tracking, the stream position is kept
+func (rs *RecordSensor) Reset() {
+	rs.lastSequence = nil
+	rs.reset = false
+}
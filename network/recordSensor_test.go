@@ -0,0 +1,323 @@
+package network
+
+import (
+	"github.com/nupic-community/htm"
+	"github.com/nupic-community/htm/encoders"
+	"github.com/nupic-community/htm/utils"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testRecords = `timestamp, consumption, count, color, weekend, reset, seq
+datetime, float, int, string, bool, int, string
+T, , , C, , R, S
+2010-07-02 00:00:00, 21.2, 1, red, false, 1, a
+2010-07-02 01:00:00, 16.4, 2, blue, false, 0, a
+2010-07-03 02:00:00.5, 4.7, 3, red, true, 0, b
+2010-07-03 03:00:00, 4.7, 5, green, true, 1, b
+`
+
+//Counts resets
+type resetCounter struct {
+	count int
+}
+
+func (rc *resetCounter) Reset() {
+	rc.count++
+}
+
+func TestRecordStream(t *testing.T) {
+	rs, err := NewRecordStream(strings.NewReader(testRecords))
+	assert.Nil(t, err)
+
+	fields := rs.Fields()
+	assert.Equal(t, 7, len(fields))
+	assert.Equal(t, FieldMeta{Name: "timestamp", Type: DateTimeField, Flag: TimestampFlag}, fields[0])
+	assert.Equal(t, FieldMeta{Name: "consumption", Type: FloatField, Flag: NoFlag}, fields[1])
+	assert.Equal(t, FieldMeta{Name: "reset", Type: IntField, Flag: ResetFlag}, fields[5])
+	field, ok := rs.FlaggedField(CategoryFlag)
+	assert.True(t, ok)
+	assert.Equal(t, "color", field.Name)
+
+	n, err := rs.Prescan(-1)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, n)
+	min, max, ok := rs.FieldRange("consumption")
+	assert.True(t, ok)
+	assert.Equal(t, 4.7, min)
+	assert.Equal(t, 21.2, max)
+	min, max, _ = rs.FieldRange("count")
+	assert.Equal(t, 1.0, min)
+	assert.Equal(t, 5.0, max)
+	_, _, ok = rs.FieldRange("color")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"blue", "green", "red"}, rs.Categories("color"))
+
+	record, err := rs.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2010, 7, 2, 0, 0, 0, 0, time.UTC), record["timestamp"])
+	assert.Equal(t, 21.2, record["consumption"])
+	assert.Equal(t, 1, record["count"])
+	assert.Equal(t, "red", record["color"])
+	assert.Equal(t, false, record["weekend"])
+
+	for rs.HasNext() {
+		record, err = rs.Next()
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, time.Date(2010, 7, 3, 3, 0, 0, 0, time.UTC), record["timestamp"])
+	_, err = rs.Next()
+	assert.Equal(t, io.EOF, err)
+
+	assert.Nil(t, rs.Rewind())
+	assert.True(t, rs.HasNext())
+}
+
+func TestRecordStreamLazy(t *testing.T) {
+	rs, err := NewRecordStream(strings.NewReader(testRecords))
+	assert.Nil(t, err)
+	_, _, ok := rs.FieldRange("consumption")
+	assert.False(t, ok)
+
+	record, err := rs.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, 21.2, record["consumption"])
+	_, _, ok = rs.FieldRange("consumption")
+	assert.False(t, ok)
+
+	// prescanned records are still returned
+	n, err := rs.Prescan(2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, n)
+	min, max, _ := rs.FieldRange("consumption")
+	assert.Equal(t, 4.7, min)
+	assert.Equal(t, 16.4, max)
+	record, _ = rs.Next()
+	assert.Equal(t, 16.4, record["consumption"])
+
+	// rewinding requires a seekable source
+	rs, _ = NewRecordStream(ioutil.NopCloser(strings.NewReader(testRecords)))
+	assert.NotNil(t, rs.Rewind())
+}
+
+func TestRecordStreamErrors(t *testing.T) {
+	_, err := NewRecordStream(strings.NewReader("a,b\nfloat,int\n"))
+	assert.NotNil(t, err)
+
+	_, err = NewRecordStream(strings.NewReader("a\ncomplex\n\n"))
+	assert.NotNil(t, err)
+
+	_, err = NewRecordStream(strings.NewReader("a\nfloat\nX\n"))
+	assert.NotNil(t, err)
+
+	// record errors surface when the record is read
+	rs, err := NewRecordStream(strings.NewReader("a,b\nfloat,int\n,\n1.5\n"))
+	assert.Nil(t, err)
+	_, err = rs.Next()
+	assert.NotNil(t, err)
+
+	rs, _ = NewRecordStream(strings.NewReader("a\nfloat\n\n1.5\nfoo\n"))
+	_, err = rs.Prescan(-1)
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "record 2"))
+	_, err = rs.Next()
+	assert.Nil(t, err)
+	assert.True(t, rs.HasNext())
+	_, err = rs.Next()
+	assert.NotNil(t, err)
+
+	// empty flag row
+	rs, err = NewRecordStream(strings.NewReader("a\nfloat\n\n1.5\n2.5"))
+	assert.Nil(t, err)
+	n, err := rs.Prescan(-1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, n)
+}
+
+func TestOpenRecordStream(t *testing.T) {
+	file, err := ioutil.TempFile("", "records")
+	assert.Nil(t, err)
+	defer os.Remove(file.Name())
+	file.WriteString(testRecords)
+	file.Close()
+
+	rs, err := OpenRecordStream(file.Name())
+	assert.Nil(t, err)
+	n, err := rs.Prescan(-1)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, n)
+	assert.Nil(t, rs.Rewind())
+	record, _ := rs.Next()
+	assert.Equal(t, 21.2, record["consumption"])
+	assert.Nil(t, rs.Close())
+
+	_, err = OpenRecordStream(file.Name() + ".missing")
+	assert.NotNil(t, err)
+}
+
+func TestRecordSensor(t *testing.T) {
+	rs, _ := NewRecordStream(strings.NewReader(testRecords))
+	params := NewRecordSensorParams()
+	sensor, err := NewRecordSensor(rs, params)
+	assert.Nil(t, err)
+
+	// reset and sequence fields are not encoded
+	var names []string
+	for _, field := range sensor.Encoder.Fields() {
+		names = append(names, field.Name)
+	}
+	assert.Equal(t, []string{"timestamp", "consumption", "count", "color", "weekend"}, names)
+
+	_, width, _ := sensor.Encoder.FieldRange("consumption")
+	assert.Equal(t, 200, width)
+	_, width, _ = sensor.Encoder.FieldRange("color")
+	assert.Equal(t, 21*4, width)
+	_, width, _ = sensor.Encoder.FieldRange("weekend")
+	assert.Equal(t, 21*3, width)
+
+	counter := new(resetCounter)
+	sensor.AddResetTarget(counter)
+
+	// reset flag
+	assert.Nil(t, sensor.Compute())
+	assert.True(t, sensor.IsReset())
+	assert.Equal(t, 1, counter.count)
+	assert.Equal(t, sensor.Encoder.OutputWidth(), len(sensor.Output("encoded")))
+	assert.True(t, utils.AnyTrue(sensor.Output("encoded")))
+	assert.Equal(t, "red", sensor.Record()["color"])
+
+	assert.Nil(t, sensor.Compute())
+	assert.False(t, sensor.IsReset())
+	assert.Equal(t, 1, counter.count)
+
+	// sequence change
+	assert.Nil(t, sensor.Compute())
+	assert.True(t, sensor.IsReset())
+	assert.Equal(t, 2, counter.count)
+
+	// weekend is encoded as a category
+	offset, width, _ := sensor.Encoder.FieldRange("weekend")
+	weekend := sensor.Output("encoded")[offset : offset+width]
+	assert.Equal(t, 21, utils.CountTrue(weekend))
+	assert.True(t, weekend[2*21])
+
+	assert.Nil(t, sensor.Compute())
+	assert.Equal(t, 3, counter.count)
+
+	assert.Equal(t, io.EOF, sensor.Compute())
+}
+
+func TestRecordSensorCustomEncoder(t *testing.T) {
+	rs, _ := NewRecordStream(strings.NewReader(testRecords))
+	params := NewRecordSensorParams()
+	params.ScalarN = 100
+	custom := newTestEncoderRegion().Encoder
+	params.Encoders["count"] = custom
+
+	sensor, err := NewRecordSensor(rs, params)
+	assert.Nil(t, err)
+	_, width, _ := sensor.Encoder.FieldRange("consumption")
+	assert.Equal(t, 100, width)
+	_, width, _ = sensor.Encoder.FieldRange("count")
+	assert.Equal(t, custom.OutputWidth(), width)
+}
+
+func TestRecordSensorFieldRanges(t *testing.T) {
+	rs, _ := NewRecordStream(strings.NewReader(testRecords))
+	params := NewRecordSensorParams()
+	params.PrescanRecords = 0
+	params.FieldRanges["consumption"] = ValueRange{Min: 0, Max: 50}
+	params.FieldRanges["count"] = ValueRange{Min: 0, Max: 10}
+	params.Categories["color"] = []string{"red"}
+	params.MaxCategories = 4
+
+	sensor, err := NewRecordSensor(rs, params)
+	assert.Nil(t, err)
+	encoder, _ := defaultFieldEncoder(rs, rs.Fields()[1], params)
+	consumption := encoder.(*encoders.ScalerEncoder)
+	assert.Equal(t, 0.0, consumption.MinVal)
+	assert.Equal(t, 50.0, consumption.MaxVal)
+	_, width, _ := sensor.Encoder.FieldRange("color")
+	assert.Equal(t, 21*5, width)
+
+	// nothing was read ahead
+	_, _, ok := rs.FieldRange("consumption")
+	assert.False(t, ok)
+	for rs.HasNext() {
+		assert.Nil(t, sensor.Compute())
+	}
+
+	// missing range without prescan
+	rs, _ = NewRecordStream(strings.NewReader(testRecords))
+	delete(params.FieldRanges, "count")
+	_, err = NewRecordSensor(rs, params)
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "no range for count"))
+
+	params.FieldRanges["count"] = ValueRange{Min: 10, Max: 10}
+	_, err = NewRecordSensor(rs, params)
+	assert.NotNil(t, err)
+	params.FieldRanges["count"] = ValueRange{Min: 0, Max: 10}
+	params.PrescanRecords = -2
+	_, err = NewRecordSensor(rs, params)
+	assert.Equal(t, "PrescanRecords", err.(*htm.ParamError).Field)
+}
+
+func TestRecordSensorPrescanPrefix(t *testing.T) {
+	rs, _ := NewRecordStream(strings.NewReader(testRecords))
+	params := NewRecordSensorParams()
+	params.PrescanRecords = 2
+
+	sensor, err := NewRecordSensor(rs, params)
+	assert.Nil(t, err)
+	encoder, _ := defaultFieldEncoder(rs, rs.Fields()[1], params)
+	consumption := encoder.(*encoders.ScalerEncoder)
+	assert.Equal(t, 16.4, consumption.MinVal)
+	assert.Equal(t, 21.2, consumption.MaxVal)
+
+	// values beyond the prefix are clipped
+	steps := 0
+	for rs.HasNext() {
+		assert.Nil(t, sensor.Compute())
+		steps++
+	}
+	assert.Equal(t, 4, steps)
+}
+
+func TestRecordSensorNetwork(t *testing.T) {
+	rs, _ := NewRecordStream(strings.NewReader(testRecords))
+	sensor, _ := NewRecordSensor(rs, NewRecordSensorParams())
+
+	spParams := htm.NewSpParams()
+	spParams.InputDimensions = []int{sensor.Encoder.OutputWidth()}
+	spParams.ColumnDimensions = []int{64}
+	spParams.PotentialRadius = spParams.InputDimensions[0]
+	spParams.NumActiveColumnsPerInhArea = 4
+	spParams.GlobalInhibition = true
+	spr := NewSpatialPoolerRegion(htm.NewSpatialPooler(spParams))
+
+	tmParams := htm.NewTemporalMemoryParams()
+	tmParams.ColumnDimensions = spParams.ColumnDimensions
+	tmParams.CellsPerColumn = 4
+	tmr := NewTemporalMemoryRegion(htm.NewTemporalMemory(tmParams))
+	sensor.AddResetTarget(tmr)
+
+	n := NewNetwork()
+	n.AddRegion("sensor", sensor)
+	n.AddRegion("sp", spr)
+	n.AddRegion("tm", tmr)
+	assert.Nil(t, n.Link("sensor", "encoded", "sp", "bottomUpIn"))
+	assert.Nil(t, n.Link("sp", "bottomUpOut", "tm", "bottomUpIn"))
+
+	steps := 0
+	for rs.HasNext() {
+		assert.Nil(t, n.Compute())
+		steps++
+	}
+	assert.Equal(t, 4, steps)
+}
@@ -0,0 +1,341 @@
+package network
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Type of a record field, the second header row of a record file
+type FieldType string
+
+const (
+	FloatField    FieldType = "float"
+	IntField      FieldType = "int"
+	StringField   FieldType = "string"
+	DateTimeField FieldType = "datetime"
+	BoolField     FieldType = "bool"
+)
+
+//Special meaning of a record field, the third header row of a record file
+type FieldFlag string
+
+const (
+	NoFlag FieldFlag = ""
+	//A true or non zero value starts a new sequence
+	ResetFlag FieldFlag = "R"
+	//A change of value starts a new sequence
+	SequenceFlag FieldFlag = "S"
+	//The record timestamp
+	TimestampFlag FieldFlag = "T"
+	//The field that is classified
+	CategoryFlag FieldFlag = "C"
+)
+
+//Name, type and flag of a record field
+type FieldMeta struct {
+	Name string
+	Type FieldType
+	Flag FieldFlag
+}
+
+//Layouts datetime fields are parsed with, in order
+var dateTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+/*
+ A record stream reads records from CSV data with a three row header
+of field names, field types and field flags, like the NuPIC file format:
+
+	timestamp,consumption,reset
+	datetime,float,int
+	T,,R
+	2010-07-02 00:00:00,21.2,1
+
+Values are parsed to float64, int, string, time.Time or bool according
+to their field type. Records are read lazily as the stream advances,
+Prescan reads records ahead to inspect the value range and categories of
+fields before encoding.
+*/
+type RecordStream struct {
+	fields []FieldMeta
+	source io.Reader
+	reader *csv.Reader
+	//records read ahead of the stream position
+	buffered []map[string]interface{}
+	//error that ended reading, returned after the buffered records
+	err error
+	//number of data rows read from the source
+	numRead int
+}
+
+/*
+	Opens the specified record file, the file stays open until the
+stream is closed
+*/
+func OpenRecordStream(path string) (*RecordStream, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	rs, err := NewRecordStream(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return rs, nil
+}
+
+/*
+	Reads the header of a record stream from CSV data, records are read
+as they are requested
+*/
+func NewRecordStream(r io.Reader) (*RecordStream, error) {
+	rs := new(RecordStream)
+	rs.source = r
+	if err := rs.readHeader(); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+//Reads the header from the current position of the source and prepares
+//reading records after it
+func (rs *RecordStream) readHeader() error {
+	buffered := bufio.NewReader(rs.source)
+
+	// the header is read line by line, csv skips the empty flag row of
+	// files without flags
+	header := make([][]string, 3)
+	for idx := range header {
+		line, err := buffered.ReadString('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			return fmt.Errorf("reading header row %v: %v", idx+1, err)
+		}
+		header[idx] = splitHeaderRow(line)
+	}
+
+	fields := make([]FieldMeta, len(header[0]))
+	for idx, name := range header[0] {
+		field := FieldMeta{Name: name}
+		if idx < len(header[1]) {
+			field.Type = FieldType(strings.ToLower(header[1][idx]))
+		}
+		if idx < len(header[2]) {
+			field.Flag = FieldFlag(strings.ToUpper(header[2][idx]))
+		}
+		switch field.Type {
+		case FloatField, IntField, StringField, DateTimeField, BoolField:
+		default:
+			return fmt.Errorf("field %v has unknown type %v", field.Name, field.Type)
+		}
+		switch field.Flag {
+		case NoFlag, ResetFlag, SequenceFlag, TimestampFlag, CategoryFlag:
+		default:
+			return fmt.Errorf("field %v has unknown flag %v", field.Name, field.Flag)
+		}
+		fields[idx] = field
+	}
+
+	rs.fields = fields
+	rs.reader = csv.NewReader(buffered)
+	rs.reader.TrimLeadingSpace = true
+	rs.reader.FieldsPerRecord = len(rs.fields)
+	rs.buffered = nil
+	rs.err = nil
+	rs.numRead = 0
+	return nil
+}
+
+//Reads and parses the next record from the source
+func (rs *RecordStream) readRecord() (map[string]interface{}, error) {
+	row, err := rs.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	rs.numRead++
+
+	record := make(map[string]interface{}, len(rs.fields))
+	for idx, field := range rs.fields {
+		value, err := parseFieldValue(field.Type, strings.TrimSpace(row[idx]))
+		if err != nil {
+			return nil, fmt.Errorf("record %v field %v: %v", rs.numRead, field.Name, err)
+		}
+		record[field.Name] = value
+	}
+	return record, nil
+}
+
+//Reads ahead until count records are buffered or reading ends, a
+//negative count reads to the end of the stream
+func (rs *RecordStream) fill(count int) {
+	for rs.err == nil && (count < 0 || len(rs.buffered) < count) {
+		record, err := rs.readRecord()
+		if err != nil {
+			rs.err = err
+			break
+		}
+		rs.buffered = append(rs.buffered, record)
+	}
+}
+
+//Splits a header row into its trimmed values
+func splitHeaderRow(line string) []string {
+	line = strings.TrimRight(line, "\r\n")
+	if len(strings.TrimSpace(line)) == 0 {
+		return nil
+	}
+	row, err := csv.NewReader(strings.NewReader(line)).Read()
+	if err != nil {
+		row = strings.Split(line, ",")
+	}
+	for idx := range row {
+		row[idx] = strings.TrimSpace(row[idx])
+	}
+	return row
+}
+
+//Parses a value of the specified type
+func parseFieldValue(fieldType FieldType, value string) (interface{}, error) {
+	switch fieldType {
+	case FloatField:
+		return strconv.ParseFloat(value, 64)
+	case IntField:
+		return strconv.Atoi(value)
+	case BoolField:
+		return strconv.ParseBool(value)
+	case DateTimeField:
+		for _, layout := range dateTimeLayouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid datetime %v", value)
+	}
+	return value, nil
+}
+
+//Returns the fields of the records in file order
+func (rs *RecordStream) Fields() []FieldMeta {
+	result := make([]FieldMeta, len(rs.fields))
+	copy(result, rs.fields)
+	return result
+}
+
+//Returns the first field with the specified flag
+func (rs *RecordStream) FlaggedField(flag FieldFlag) (FieldMeta, bool) {
+	for _, field := range rs.fields {
+		if field.Flag == flag {
+			return field, true
+		}
+	}
+	return FieldMeta{}, false
+}
+
+/*
+	Reads up to limit records ahead without advancing the stream, so
+FieldRange and Categories can inspect them. A negative limit reads the
+whole stream. Returns the number of records read ahead, and the error that
+ended reading unless it is the end of the stream.
+*/
+func (rs *RecordStream) Prescan(limit int) (int, error) {
+	rs.fill(limit)
+	if rs.err != nil && rs.err != io.EOF {
+		return len(rs.buffered), rs.err
+	}
+	return len(rs.buffered), nil
+}
+
+//Returns true if there are records left to read, or a read error to
+//report
+func (rs *RecordStream) HasNext() bool {
+	rs.fill(1)
+	return len(rs.buffered) > 0 || rs.err != io.EOF
+}
+
+/*
+	Returns the next record keyed by field name, or io.EOF once all
+records have been read
+*/
+func (rs *RecordStream) Next() (map[string]interface{}, error) {
+	rs.fill(1)
+	if len(rs.buffered) == 0 {
+		return nil, rs.err
+	}
+	record := rs.buffered[0]
+	rs.buffered = rs.buffered[1:]
+	return record, nil
+}
+
+//Restarts the stream at the first record, the source must be seekable
+func (rs *RecordStream) Rewind() error {
+	seeker, ok := rs.source.(io.Seeker)
+	if !ok {
+		return fmt.Errorf("record source %T can not be rewound", rs.source)
+	}
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return rs.readHeader()
+}
+
+//Closes the source of the stream if it is closable, eg a file opened by
+//OpenRecordStream
+func (rs *RecordStream) Close() error {
+	if closer, ok := rs.source.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+/*
+	Returns the smallest and largest value of a float or int field in the
+records read ahead, see Prescan
+*/
+func (rs *RecordStream) FieldRange(name string) (min float64, max float64, ok bool) {
+	for idx, record := range rs.buffered {
+		var val float64
+		switch v := record[name].(type) {
+		case float64:
+			val = v
+		case int:
+			val = float64(v)
+		default:
+			return 0, 0, false
+		}
+		if idx == 0 || val < min {
+			min = val
+		}
+		if idx == 0 || val > max {
+			max = val
+		}
+	}
+	return min, max, len(rs.buffered) > 0
+}
+
+//Returns the sorted distinct values of a string field in the records
+//read ahead, see Prescan
+func (rs *RecordStream) Categories(name string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, record := range rs.buffered {
+		val, ok := record[name].(string)
+		if !ok || seen[val] {
+			continue
+		}
+		seen[val] = true
+		result = append(result, val)
+	}
+	sort.Strings(result)
+	return result
+}
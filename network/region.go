@@ -0,0 +1,59 @@
+package network
+
+import (
+	"fmt"
+)
+
+/*
+	A region is a node of a network. Regions read named []bool inputs and
+produce named []bool outputs each time they are computed.
+*/
+type Region interface {
+	//Names of the inputs the region reads
+	Inputs() []string
+	//Names of the outputs the region produces
+	Outputs() []string
+	//Sets the value of an input for the next compute
+	SetInput(name string, value []bool) error
+	//Returns the value of an output of the last compute
+	Output(name string) []bool
+	//Processes the current inputs
+	Compute() error
+	//Resets sequence state
+	Reset()
+}
+
+/*
+	Input bookkeeping shared by the region adapters
+*/
+type regionInputs struct {
+	names  []string
+	values map[string][]bool
+}
+
+func newRegionInputs(names ...string) regionInputs {
+	return regionInputs{names: names, values: make(map[string][]bool, len(names))}
+}
+
+func (ri *regionInputs) Inputs() []string {
+	return ri.names
+}
+
+func (ri *regionInputs) SetInput(name string, value []bool) error {
+	for _, n := range ri.names {
+		if n == name {
+			ri.values[name] = value
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown input %v", name)
+}
+
+//Returns the value of an input, or an error if it has not been set
+func (ri *regionInputs) input(name string) ([]bool, error) {
+	value, ok := ri.values[name]
+	if !ok {
+		return nil, fmt.Errorf("input %v not set", name)
+	}
+	return value, nil
+}
@@ -0,0 +1,58 @@
+package network
+
+import (
+	"github.com/nupic-community/htm"
+	"github.com/nupic-community/htm/utils"
+)
+
+/*
+	Region wrapping a spatial pooler. Reads the "bottomUpIn" input and
+produces the active columns as "bottomUpOut".
+*/
+type SpatialPoolerRegion struct {
+	regionInputs
+	SpatialPooler *htm.SpatialPooler
+	Learn         bool
+
+	output []bool
+}
+
+/*
+	Intializes a new spatial pooler region
+*/
+func NewSpatialPoolerRegion(sp *htm.SpatialPooler) *SpatialPoolerRegion {
+	spr := new(SpatialPoolerRegion)
+	spr.regionInputs = newRegionInputs("bottomUpIn")
+	spr.SpatialPooler = sp
+	spr.Learn = true
+	spr.output = make([]bool, utils.ProdInt(sp.ColumnDimensions))
+	return spr
+}
+
+func (spr *SpatialPoolerRegion) Outputs() []string {
+	return []string{"bottomUpOut"}
+}
+
+func (spr *SpatialPoolerRegion) Output(name string) []bool {
+	if name == "bottomUpOut" {
+		return spr.output
+	}
+	return nil
+}
+
+func (spr *SpatialPoolerRegion) Compute() error {
+	input, err := spr.input("bottomUpIn")
+	if err != nil {
+		return err
+	}
+	if err := spr.SpatialPooler.ValidateInput(input); err != nil {
+		return err
+	}
+
+	spr.output, _ = spr.SpatialPooler.Compute(input, spr.Learn)
+	return nil
+}
+
+//The spatial pooler has no sequence state
+func (spr *SpatialPoolerRegion) Reset() {
+}
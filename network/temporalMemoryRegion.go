@@ -0,0 +1,92 @@
+package network
+
+import (
+	"github.com/nupic-community/htm"
+	"github.com/nupic-community/htm/utils"
+)
+
+/*
+	Region wrapping a temporal memory. Reads the dense active columns from
+"bottomUpIn" and produces the dense "activeCells" and "predictiveCells",
+and "predictedActiveCells", the active cells that were predicted by the
+previous compute.
+*/
+type TemporalMemoryRegion struct {
+	regionInputs
+	TemporalMemory *htm.TemporalMemory
+	Learn          bool
+
+	activeCells          []bool
+	predictiveCells      []bool
+	predictedActiveCells []bool
+}
+
+/*
+	Intializes a new temporal memory region
+*/
+func NewTemporalMemoryRegion(tm *htm.TemporalMemory) *TemporalMemoryRegion {
+	tmr := new(TemporalMemoryRegion)
+	tmr.regionInputs = newRegionInputs("bottomUpIn")
+	tmr.TemporalMemory = tm
+	tmr.Learn = true
+	numCells := tm.Connections.NumberOfcells()
+	tmr.activeCells = make([]bool, numCells)
+	tmr.predictiveCells = make([]bool, numCells)
+	tmr.predictedActiveCells = make([]bool, numCells)
+	return tmr
+}
+
+func (tmr *TemporalMemoryRegion) Outputs() []string {
+	return []string{"activeCells", "predictiveCells", "predictedActiveCells"}
+}
+
+func (tmr *TemporalMemoryRegion) Output(name string) []bool {
+	switch name {
+	case "activeCells":
+		return tmr.activeCells
+	case "predictiveCells":
+		return tmr.predictiveCells
+	case "predictedActiveCells":
+		return tmr.predictedActiveCells
+	}
+	return nil
+}
+
+//Returns a dense slice with the specified cells set
+func denseCells(numCells int, cells []int) []bool {
+	result := make([]bool, numCells)
+	utils.SetIdxBool(result, cells, true)
+	return result
+}
+
+func (tmr *TemporalMemoryRegion) Compute() error {
+	input, err := tmr.input("bottomUpIn")
+	if err != nil {
+		return err
+	}
+
+	tm := tmr.TemporalMemory
+	prevPredictive := tmr.predictiveCells
+	tm.Compute(utils.OnIndices(input), tmr.Learn)
+
+	numCells := tm.Connections.NumberOfcells()
+	tmr.activeCells = denseCells(numCells, tm.ActiveCells)
+	tmr.predictiveCells = denseCells(numCells, tm.PredictiveCells)
+	tmr.predictedActiveCells = make([]bool, numCells)
+	for _, cell := range tm.ActiveCells {
+		tmr.predictedActiveCells[cell] = prevPredictive[cell]
+	}
+	return nil
+}
+
+//Returns the anomaly score of the last compute
+func (tmr *TemporalMemoryRegion) AnomalyScore() float64 {
+	return tmr.TemporalMemory.AnomalyScore()
+}
+
+func (tmr *TemporalMemoryRegion) Reset() {
+	tmr.TemporalMemory.Reset()
+	utils.FillSliceBool(tmr.activeCells, false)
+	utils.FillSliceBool(tmr.predictiveCells, false)
+	utils.FillSliceBool(tmr.predictedActiveCells, false)
+}
@@ -0,0 +1,61 @@
+package network
+
+import (
+	"github.com/nupic-community/htm"
+)
+
+/*
+	Region wrapping a temporal pooler. Reads the active columns from
+"bottomUpIn" and produces the pooler output as "bottomUpOut".
+*/
+type TemporalPoolerRegion struct {
+	regionInputs
+	TemporalPooler *htm.TemporalPooler
+	Learn          bool
+	Infer          bool
+
+	output []bool
+}
+
+/*
+	Intializes a new temporal pooler region
+*/
+func NewTemporalPoolerRegion(tp *htm.TemporalPooler) *TemporalPoolerRegion {
+	tpr := new(TemporalPoolerRegion)
+	tpr.regionInputs = newRegionInputs("bottomUpIn")
+	tpr.TemporalPooler = tp
+	tpr.Learn = true
+	tpr.Infer = true
+	height, width := tp.DynamicState.InfActiveState.Dims()
+	tpr.output = make([]bool, height*width)
+	return tpr
+}
+
+func (tpr *TemporalPoolerRegion) Outputs() []string {
+	return []string{"bottomUpOut"}
+}
+
+func (tpr *TemporalPoolerRegion) Output(name string) []bool {
+	if name == "bottomUpOut" {
+		return tpr.output
+	}
+	return nil
+}
+
+func (tpr *TemporalPoolerRegion) Compute() error {
+	input, err := tpr.input("bottomUpIn")
+	if err != nil {
+		return err
+	}
+	tpr.output = tpr.TemporalPooler.Compute(input, tpr.Learn, tpr.Infer)
+	return nil
+}
+
+//Returns the anomaly score of the last compute
+func (tpr *TemporalPoolerRegion) AnomalyScore() float64 {
+	return tpr.TemporalPooler.AnomalyScore()
+}
+
+func (tpr *TemporalPoolerRegion) Reset() {
+	tpr.TemporalPooler.Reset()
+}
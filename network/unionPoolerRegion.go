@@ -0,0 +1,65 @@
+package network
+
+import (
+	"github.com/nupic-community/htm/utils"
+)
+
+/*
+	Region that pools its input over time. The "bottomUpOut" output is
+the union of the last Window "bottomUpIn" inputs, so it changes slower
+than its input and stays stable while a learned sequence plays out.
+*/
+type UnionPoolerRegion struct {
+	regionInputs
+	Window int
+
+	history [][]bool
+	output  []bool
+}
+
+/*
+	Intializes a new union pooler region for inputs of the specified width
+*/
+func NewUnionPoolerRegion(width int, window int) *UnionPoolerRegion {
+	upr := new(UnionPoolerRegion)
+	upr.regionInputs = newRegionInputs("bottomUpIn")
+	upr.Window = window
+	upr.output = make([]bool, width)
+	return upr
+}
+
+func (upr *UnionPoolerRegion) Outputs() []string {
+	return []string{"bottomUpOut"}
+}
+
+func (upr *UnionPoolerRegion) Output(name string) []bool {
+	if name == "bottomUpOut" {
+		return upr.output
+	}
+	return nil
+}
+
+func (upr *UnionPoolerRegion) Compute() error {
+	input, err := upr.input("bottomUpIn")
+	if err != nil {
+		return err
+	}
+
+	upr.history = append(upr.history, input)
+	if len(upr.history) > upr.Window {
+		upr.history = upr.history[len(upr.history)-upr.Window:]
+	}
+
+	output := make([]bool, len(input))
+	for _, past := range upr.history {
+		output = utils.OrBool(output, past)
+	}
+	upr.output = output
+	return nil
+}
+
+//Clears the pooled history
+func (upr *UnionPoolerRegion) Reset() {
+	upr.history = nil
+	utils.FillSliceBool(upr.output, false)
+}
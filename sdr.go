@@ -0,0 +1,379 @@
+package htm
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+/*
+ A sparse distributed representation. An SDR has fixed dimensions and
+can be read or written as a dense []bool, as sorted flat indices of the
+active bits or as coordinates of the active bits per dimension. Views
+are computed from whichever one was set last and cached until the next
+write, callers must not modify returned slices.
+*/
+type SDR struct {
+	dimensions []int
+	size       int
+
+	dense       []bool
+	sparse      []int
+	coordinates [][]int
+
+	hasDense       bool
+	hasSparse      bool
+	hasCoordinates bool
+}
+
+/*
+	Intializes a new SDR of the specified dimensions with no active bits
+*/
+func NewSDR(dimensions ...int) *SDR {
+	if len(dimensions) == 0 {
+		panic("SDR must have at least 1 dimension")
+	}
+
+	s := new(SDR)
+	s.dimensions = make([]int, len(dimensions))
+	copy(s.dimensions, dimensions)
+	s.size = 1
+	for _, dim := range dimensions {
+		if dim < 0 {
+			panic("SDR dimensions must not be negative")
+		}
+		s.size *= dim
+	}
+	s.Zero()
+	return s
+}
+
+//Intializes a new SDR of the specified dimensions from a dense slice
+func NewSDRFromDense(dense []bool, dimensions ...int) *SDR {
+	if len(dimensions) == 0 {
+		dimensions = []int{len(dense)}
+	}
+	s := NewSDR(dimensions...)
+	s.SetDense(dense)
+	return s
+}
+
+//Intializes a new SDR of the specified dimensions from flat indices
+func NewSDRFromSparse(sparse []int, dimensions ...int) *SDR {
+	s := NewSDR(dimensions...)
+	s.SetSparse(sparse)
+	return s
+}
+
+//Returns the dimensions of the SDR
+func (s *SDR) Dimensions() []int {
+	result := make([]int, len(s.dimensions))
+	copy(result, s.dimensions)
+	return result
+}
+
+//Returns the total number of bits
+func (s *SDR) Size() int {
+	return s.size
+}
+
+//Clears all cached views
+func (s *SDR) invalidate() {
+	s.hasDense = false
+	s.hasSparse = false
+	s.hasCoordinates = false
+}
+
+//Deactivates all bits
+func (s *SDR) Zero() {
+	s.invalidate()
+	s.sparse = []int{}
+	s.hasSparse = true
+}
+
+/*
+	Sets the active bits from a dense slice, the slice is copied
+*/
+func (s *SDR) SetDense(dense []bool) {
+	if len(dense) != s.size {
+		panic(fmt.Sprintf("dense SDR has %v bits, expected %v", len(dense), s.size))
+	}
+	s.invalidate()
+	s.dense = make([]bool, s.size)
+	copy(s.dense, dense)
+	s.hasDense = true
+}
+
+/*
+	Sets the active bits from flat indices, the indices are copied,
+sorted and deduplicated
+*/
+func (s *SDR) SetSparse(sparse []int) {
+	result := make([]int, len(sparse))
+	copy(result, sparse)
+	sort.Ints(result)
+
+	write := 0
+	for i, idx := range result {
+		if idx < 0 || idx >= s.size {
+			panic(fmt.Sprintf("SDR index %v out of range %v", idx, s.size))
+		}
+		if i > 0 && idx == result[i-1] {
+			continue
+		}
+		result[write] = idx
+		write++
+	}
+
+	s.invalidate()
+	s.sparse = result[:write]
+	s.hasSparse = true
+}
+
+/*
+	Sets the active bits from coordinates, coordinates[d][i] is the
+position of the i'th active bit along dimension d
+*/
+func (s *SDR) SetCoordinates(coordinates [][]int) {
+	if len(coordinates) != len(s.dimensions) {
+		panic(fmt.Sprintf("SDR coordinates have %v dimensions, expected %v", len(coordinates), len(s.dimensions)))
+	}
+
+	sparse := make([]int, len(coordinates[0]))
+	for i := range sparse {
+		idx := 0
+		for d, dim := range s.dimensions {
+			if len(coordinates[d]) != len(sparse) {
+				panic("SDR coordinates must have the same length in every dimension")
+			}
+			coord := coordinates[d][i]
+			if coord < 0 || coord >= dim {
+				panic(fmt.Sprintf("SDR coordinate %v out of range %v", coord, dim))
+			}
+			idx = idx*dim + coord
+		}
+		sparse[i] = idx
+	}
+	s.SetSparse(sparse)
+}
+
+//Returns the SDR as a dense slice
+func (s *SDR) Dense() []bool {
+	if !s.hasDense {
+		s.dense = make([]bool, s.size)
+		for _, idx := range s.Sparse() {
+			s.dense[idx] = true
+		}
+		s.hasDense = true
+	}
+	return s.dense
+}
+
+//Returns the sorted flat indices of the active bits
+func (s *SDR) Sparse() []int {
+	if !s.hasSparse {
+		s.sparse = make([]int, 0, s.size/10)
+		for idx, val := range s.dense {
+			if val {
+				s.sparse = append(s.sparse, idx)
+			}
+		}
+		s.hasSparse = true
+	}
+	return s.sparse
+}
+
+//Returns the coordinates of the active bits per dimension
+func (s *SDR) Coordinates() [][]int {
+	if !s.hasCoordinates {
+		sparse := s.Sparse()
+		s.coordinates = make([][]int, len(s.dimensions))
+		for d := range s.coordinates {
+			s.coordinates[d] = make([]int, len(sparse))
+		}
+		for i, idx := range sparse {
+			for d := len(s.dimensions) - 1; d >= 0; d-- {
+				s.coordinates[d][i] = idx % s.dimensions[d]
+				idx /= s.dimensions[d]
+			}
+		}
+		s.hasCoordinates = true
+	}
+	return s.coordinates
+}
+
+//Returns the number of active bits
+func (s *SDR) NumActive() int {
+	return len(s.Sparse())
+}
+
+//Returns the fraction of active bits
+func (s *SDR) Sparsity() float64 {
+	if s.size == 0 {
+		return 0
+	}
+	return float64(s.NumActive()) / float64(s.size)
+}
+
+func (s *SDR) validateSize(other *SDR) {
+	if s.size != other.size {
+		panic(fmt.Sprintf("SDR sizes do not match %v != %v", s.size, other.size))
+	}
+}
+
+//Returns the number of bits active in both SDRs
+func (s *SDR) Overlap(other *SDR) int {
+	s.validateSize(other)
+	a, b := s.Sparse(), other.Sparse()
+	overlap := 0
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			overlap++
+			i++
+			j++
+		}
+	}
+	return overlap
+}
+
+/*
+	Merges the sorted active bits of both SDRs, keep selects bits by
+whether they are in a and in b
+*/
+func (s *SDR) merge(other *SDR, keep func(inA bool, inB bool) bool) *SDR {
+	s.validateSize(other)
+	a, b := s.Sparse(), other.Sparse()
+	result := make([]int, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case j == len(b) || (i < len(a) && a[i] < b[j]):
+			if keep(true, false) {
+				result = append(result, a[i])
+			}
+			i++
+		case i == len(a) || b[j] < a[i]:
+			if keep(false, true) {
+				result = append(result, b[j])
+			}
+			j++
+		default:
+			if keep(true, true) {
+				result = append(result, a[i])
+			}
+			i++
+			j++
+		}
+	}
+
+	sdr := NewSDR(s.dimensions...)
+	sdr.sparse = result
+	return sdr
+}
+
+//Returns the bits active in either SDR
+func (s *SDR) Union(other *SDR) *SDR {
+	return s.merge(other, func(inA, inB bool) bool { return inA || inB })
+}
+
+//Returns the bits active in both SDRs
+func (s *SDR) Intersection(other *SDR) *SDR {
+	return s.merge(other, func(inA, inB bool) bool { return inA && inB })
+}
+
+//Returns the bits active in this SDR but not in other
+func (s *SDR) Difference(other *SDR) *SDR {
+	return s.merge(other, func(inA, inB bool) bool { return inA && !inB })
+}
+
+/*
+	Returns a copy keeping a random fraction of the active bits
+*/
+func (s *SDR) Subsample(fraction float64, rng *rand.Rand) *SDR {
+	sparse := s.Sparse()
+	keep := int(float64(len(sparse))*fraction + 0.5)
+	if keep > len(sparse) {
+		keep = len(sparse)
+	}
+
+	perm := rng.Perm(len(sparse))
+	result := make([]int, keep)
+	for i := 0; i < keep; i++ {
+		result[i] = sparse[perm[i]]
+	}
+	return NewSDRFromSparse(result, s.dimensions...)
+}
+
+/*
+	Returns a copy with a fraction of the active bits moved to random
+inactive positions, the number of active bits is preserved
+*/
+func (s *SDR) AddNoise(fraction float64, rng *rand.Rand) *SDR {
+	dense := s.Dense()
+	sparse := s.Sparse()
+
+	inactive := make([]int, 0, s.size-len(sparse))
+	for idx, val := range dense {
+		if !val {
+			inactive = append(inactive, idx)
+		}
+	}
+
+	moves := int(float64(len(sparse))*fraction + 0.5)
+	if moves > len(inactive) {
+		moves = len(inactive)
+	}
+	if moves > len(sparse) {
+		moves = len(sparse)
+	}
+
+	result := make([]int, len(sparse))
+	copy(result, sparse)
+	turnOff := rng.Perm(len(result))[:moves]
+	turnOn := rng.Perm(len(inactive))[:moves]
+	for i := 0; i < moves; i++ {
+		result[turnOff[i]] = inactive[turnOn[i]]
+	}
+	return NewSDRFromSparse(result, s.dimensions...)
+}
+
+//Returns true if both SDRs have the same dimensions and active bits
+func (s *SDR) Equal(other *SDR) bool {
+	if len(s.dimensions) != len(other.dimensions) {
+		return false
+	}
+	for idx, dim := range s.dimensions {
+		if other.dimensions[idx] != dim {
+			return false
+		}
+	}
+
+	a, b := s.Sparse(), other.Sparse()
+	if len(a) != len(b) {
+		return false
+	}
+	for idx := range a {
+		if a[idx] != b[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+//Returns a copy of the SDR
+func (s *SDR) Copy() *SDR {
+	return NewSDRFromSparse(s.Sparse(), s.dimensions...)
+}
+
+func (s *SDR) String() string {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "SDR%v %v", s.dimensions, s.Sparse())
+	return buffer.String()
+}
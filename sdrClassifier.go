@@ -0,0 +1,327 @@
+package htm
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+/*
+	Params for the SDR classifier
+
+Steps -- Number of steps into the future to learn and predict, eg {1, 5}.
+
+Alpha -- Learning rate of the weight matrices.
+
+ActValueAlpha -- Rate of the moving average used to track the actual value
+of each bucket.
+*/
+type SDRClassifierParams struct {
+	Steps         []int
+	Alpha         float64
+	ActValueAlpha float64
+	Verbosity     int
+}
+
+//Create default SDR classifier params
+func NewSDRClassifierParams() *SDRClassifierParams {
+	p := new(SDRClassifierParams)
+
+	p.Steps = []int{1}
+	p.Alpha = 0.001
+	p.ActValueAlpha = 0.3
+	p.Verbosity = 0
+
+	return p
+}
+
+//Validates params, returns a *ParamError naming the first invalid field
+func (p *SDRClassifierParams) Validate() error {
+	if len(p.Steps) == 0 {
+		return &ParamError{Field: "Steps", Reason: "must contain at least 1 step"}
+	}
+	for _, step := range p.Steps {
+		if step < 0 {
+			return &ParamError{Field: "Steps", Reason: "must not be negative"}
+		}
+	}
+	if p.Alpha <= 0 {
+		return &ParamError{Field: "Alpha", Reason: "must be greater than 0"}
+	}
+	if p.ActValueAlpha < 0 || p.ActValueAlpha > 1 {
+		return &ParamError{Field: "ActValueAlpha", Reason: "must be between 0 and 1"}
+	}
+	return nil
+}
+
+//Active input bits seen at a learning iteration
+type classifierHistoryEntry struct {
+	Iteration int
+	PatternNZ []int
+}
+
+/*
+	Result of an SDR classifier inference. ActualValues contains the
+value of each bucket, Probabilities the likelihood of each bucket for
+each predicted step.
+*/
+type ClassifierResult struct {
+	ActualValues  []interface{}
+	Probabilities map[int][]float64
+}
+
+/*
+	Returns the actual value of the most likely bucket for the specified
+step and its probability
+*/
+func (cr *ClassifierResult) BestValue(step int) (value interface{}, probability float64) {
+	probs, ok := cr.Probabilities[step]
+	if !ok || len(probs) == 0 {
+		return nil, 0
+	}
+
+	best := 0
+	for idx, prob := range probs {
+		if prob > probs[best] {
+			best = idx
+		}
+	}
+
+	return cr.ActualValues[best], probs[best]
+}
+
+/*
+ The SDR classifier maps the active cells of a temporal memory or pooler
+to a probability distribution over the buckets of an encoder, for each of
+the configured number of steps into the future. Each step is a single
+layer network with softmax output whose weights are learned with gradient
+descent on the classification error.
+*/
+type SDRClassifier struct {
+	params SDRClassifierParams
+
+	maxSteps       int
+	learnIteration int
+	//offset between record numbers and learn iterations, -1 if unset
+	recordNumMinusLearnIteration int
+	patternNZHistory             []classifierHistoryEntry
+
+	maxInputIdx  int
+	maxBucketIdx int
+	//step -> input bit -> bucket weights
+	weights      map[int][][]float64
+	actualValues []interface{}
+}
+
+/*
+	Intializes a new SDR classifier
+*/
+func NewSDRClassifier(params *SDRClassifierParams) *SDRClassifier {
+	if err := params.Validate(); err != nil {
+		panic(err)
+	}
+
+	c := new(SDRClassifier)
+	c.params = *params
+	c.params.Steps = make([]int, len(params.Steps))
+	copy(c.params.Steps, params.Steps)
+	sort.Ints(c.params.Steps)
+
+	c.maxSteps = c.params.Steps[len(c.params.Steps)-1] + 1
+	c.recordNumMinusLearnIteration = -1
+	c.patternNZHistory = make([]classifierHistoryEntry, 0, c.maxSteps)
+
+	c.weights = make(map[int][][]float64, len(c.params.Steps))
+	for _, step := range c.params.Steps {
+		c.weights[step] = [][]float64{{0}}
+	}
+	c.actualValues = []interface{}{nil}
+
+	return c
+}
+
+//Returns the steps the classifier predicts
+func (c *SDRClassifier) Steps() []int {
+	result := make([]int, len(c.params.Steps))
+	copy(result, c.params.Steps)
+	return result
+}
+
+/*
+	Processes one input record. patternNZ are the indices of the active
+input bits, bucketIdx and actValue the encoder bucket and actual value of
+the record. Pass a negative bucketIdx if the record has no classification.
+When infer is set, returns the predicted distributions computed before
+learning from this record.
+*/
+func (c *SDRClassifier) Compute(recordNum int, patternNZ []int, bucketIdx int,
+	actValue interface{}, learn bool, infer bool) *ClassifierResult {
+
+	if c.recordNumMinusLearnIteration == -1 {
+		c.recordNumMinusLearnIteration = recordNum - c.learnIteration
+	}
+	c.learnIteration = recordNum - c.recordNumMinusLearnIteration
+
+	if c.params.Verbosity >= 1 {
+		fmt.Printf("classifier learnIteration: %v patternNZ: %v bucket: %v value: %v \n",
+			c.learnIteration, patternNZ, bucketIdx, actValue)
+	}
+
+	// Store pattern in history
+	c.patternNZHistory = append(c.patternNZHistory, classifierHistoryEntry{c.learnIteration, patternNZ})
+	if len(c.patternNZHistory) > c.maxSteps {
+		c.patternNZHistory = c.patternNZHistory[len(c.patternNZHistory)-c.maxSteps:]
+	}
+
+	// Grow weights to cover all input bits
+	for _, bit := range patternNZ {
+		if bit > c.maxInputIdx {
+			c.growWeights(bit, c.maxBucketIdx)
+		}
+	}
+
+	var result *ClassifierResult
+	if infer {
+		result = c.Infer(patternNZ, actValue)
+	}
+
+	if learn && bucketIdx >= 0 {
+		c.learn(bucketIdx, actValue)
+	}
+
+	return result
+}
+
+//Processes one input record whose active input bits are given as an SDR,
+//see Compute
+func (c *SDRClassifier) ComputeSDR(recordNum int, pattern *SDR, bucketIdx int,
+	actValue interface{}, learn bool, infer bool) *ClassifierResult {
+	return c.Compute(recordNum, pattern.Sparse(), bucketIdx, actValue, learn, infer)
+}
+
+/*
+	Returns the predicted distributions for the specified active input
+bits. defaultValue is reported for buckets that have no actual value yet.
+*/
+func (c *SDRClassifier) Infer(patternNZ []int, defaultValue interface{}) *ClassifierResult {
+	result := new(ClassifierResult)
+
+	result.ActualValues = make([]interface{}, len(c.actualValues))
+	for idx, val := range c.actualValues {
+		if val == nil {
+			val = defaultValue
+		}
+		result.ActualValues[idx] = val
+	}
+
+	result.Probabilities = make(map[int][]float64, len(c.params.Steps))
+	for _, step := range c.params.Steps {
+		result.Probabilities[step] = c.inferSingleStep(patternNZ, c.weights[step])
+	}
+
+	return result
+}
+
+/*
+	Computes the softmax of the summed weights of the active input bits
+*/
+func (c *SDRClassifier) inferSingleStep(patternNZ []int, weights [][]float64) []float64 {
+	activation := make([]float64, c.maxBucketIdx+1)
+	for _, bit := range patternNZ {
+		if bit > c.maxInputIdx {
+			continue
+		}
+		for idx, w := range weights[bit] {
+			activation[idx] += w
+		}
+	}
+
+	// subtract the max for numerical stability
+	maxActivation := math.Inf(-1)
+	for _, a := range activation {
+		maxActivation = math.Max(maxActivation, a)
+	}
+
+	sum := 0.0
+	for idx, a := range activation {
+		activation[idx] = math.Exp(a - maxActivation)
+		sum += activation[idx]
+	}
+	for idx := range activation {
+		activation[idx] /= sum
+	}
+
+	return activation
+}
+
+/*
+	Updates the actual value of bucketIdx and the weights of every step
+that has a pattern in history with the matching age
+*/
+func (c *SDRClassifier) learn(bucketIdx int, actValue interface{}) {
+	if bucketIdx > c.maxBucketIdx {
+		c.growWeights(c.maxInputIdx, bucketIdx)
+	}
+
+	c.updateActualValue(bucketIdx, actValue)
+
+	for _, entry := range c.patternNZHistory {
+		nSteps := c.learnIteration - entry.Iteration
+		weights, ok := c.weights[nSteps]
+		if !ok {
+			continue
+		}
+
+		// error = target distribution - predicted distribution
+		dist := c.inferSingleStep(entry.PatternNZ, weights)
+		for idx := range dist {
+			dist[idx] = -dist[idx]
+		}
+		dist[bucketIdx] += 1.0
+
+		for _, bit := range entry.PatternNZ {
+			for idx, err := range dist {
+				weights[bit][idx] += c.params.Alpha * err
+			}
+		}
+	}
+}
+
+/*
+	Tracks the actual value of a bucket, numeric values are smoothed with
+a moving average, other values replace the previous one
+*/
+func (c *SDRClassifier) updateActualValue(bucketIdx int, actValue interface{}) {
+	prev := c.actualValues[bucketIdx]
+	prevVal, prevNumeric := prev.(float64)
+	actVal, actNumeric := actValue.(float64)
+
+	if prev != nil && prevNumeric && actNumeric {
+		alpha := c.params.ActValueAlpha
+		c.actualValues[bucketIdx] = (1.0-alpha)*prevVal + alpha*actVal
+	} else {
+		c.actualValues[bucketIdx] = actValue
+	}
+}
+
+//Grows the weight matrices and actual values to the specified size
+func (c *SDRClassifier) growWeights(maxInputIdx, maxBucketIdx int) {
+	for step, weights := range c.weights {
+		for len(weights) <= maxInputIdx {
+			weights = append(weights, make([]float64, c.maxBucketIdx+1))
+		}
+		for bit := range weights {
+			for len(weights[bit]) <= maxBucketIdx {
+				weights[bit] = append(weights[bit], 0)
+			}
+		}
+		c.weights[step] = weights
+	}
+
+	for len(c.actualValues) <= maxBucketIdx {
+		c.actualValues = append(c.actualValues, nil)
+	}
+
+	c.maxInputIdx = maxInputIdx
+	c.maxBucketIdx = maxBucketIdx
+}
@@ -0,0 +1,92 @@
+package htm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSDRClassifierSingleValue(t *testing.T) {
+	p := NewSDRClassifierParams()
+	p.Alpha = 0.1
+	c := NewSDRClassifier(p)
+
+	var result *ClassifierResult
+	for recordNum := 0; recordNum < 10; recordNum++ {
+		result = c.Compute(recordNum, []int{1, 5, 9}, 4, 34.7, true, true)
+	}
+
+	value, prob := result.BestValue(1)
+	assert.Equal(t, 34.7, value)
+	assert.True(t, prob > 0.5)
+	assert.Equal(t, 5, len(result.Probabilities[1]))
+	assert.Equal(t, 5, len(result.ActualValues))
+}
+
+func TestSDRClassifierMultiStep(t *testing.T) {
+	p := NewSDRClassifierParams()
+	p.Steps = []int{1, 2}
+	p.Alpha = 0.5
+	c := NewSDRClassifier(p)
+	assert.Equal(t, []int{1, 2}, c.Steps())
+
+	// Sequence of three patterns A -> B -> C
+	patterns := [][]int{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}
+	values := []float64{10, 20, 30}
+
+	recordNum := 0
+	for i := 0; i < 50; i++ {
+		for idx := range patterns {
+			c.Compute(recordNum, patterns[idx], idx, values[idx], true, false)
+			recordNum++
+		}
+	}
+
+	result := c.Compute(recordNum, patterns[0], 0, values[0], false, true)
+
+	value, _ := result.BestValue(1)
+	assert.Equal(t, 20.0, value)
+	value, _ = result.BestValue(2)
+	assert.Equal(t, 30.0, value)
+
+	sum := 0.0
+	for _, prob := range result.Probabilities[1] {
+		sum += prob
+	}
+	assert.InDelta(t, 1.0, sum, 1e-9)
+}
+
+func TestSDRClassifierCategories(t *testing.T) {
+	p := NewSDRClassifierParams()
+	p.Alpha = 0.5
+	c := NewSDRClassifier(p)
+
+	for recordNum := 0; recordNum < 20; recordNum += 2 {
+		c.Compute(recordNum, []int{1, 2}, 1, "a", true, false)
+		c.Compute(recordNum+1, []int{3, 4}, 2, "b", true, false)
+	}
+
+	result := c.Infer([]int{3, 4}, nil)
+	value, _ := result.BestValue(1)
+	assert.Equal(t, "a", value)
+
+	// No classification, nothing learned
+	c.Compute(20, []int{1, 2}, -1, nil, true, false)
+	value, _ = c.Infer([]int{1, 2}, nil).BestValue(1)
+	assert.Equal(t, "b", value)
+}
+
+func TestSDRClassifierParamsValidate(t *testing.T) {
+	p := NewSDRClassifierParams()
+	assert.Nil(t, p.Validate())
+
+	p.Steps = []int{}
+	err, ok := p.Validate().(*ParamError)
+	assert.True(t, ok)
+	assert.Equal(t, "Steps", err.Field)
+
+	p = NewSDRClassifierParams()
+	p.Alpha = 0
+	err, ok = p.Validate().(*ParamError)
+	assert.True(t, ok)
+	assert.Equal(t, "Alpha", err.Field)
+}
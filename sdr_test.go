@@ -0,0 +1,115 @@
+package htm
+
+import (
+	"github.com/nupic-community/htm/utils"
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+func TestSDRViews(t *testing.T) {
+	s := NewSDR(3, 4)
+	assert.Equal(t, 12, s.Size())
+	assert.Equal(t, []int{3, 4}, s.Dimensions())
+	assert.Equal(t, 0, s.NumActive())
+
+	s.SetSparse([]int{11, 1, 5, 1})
+	assert.Equal(t, []int{1, 5, 11}, s.Sparse())
+	assert.Equal(t, [][]int{{0, 1, 2}, {1, 1, 3}}, s.Coordinates())
+
+	dense := s.Dense()
+	assert.Equal(t, 12, len(dense))
+	assert.True(t, dense[1])
+	assert.True(t, dense[5])
+	assert.True(t, dense[11])
+	assert.False(t, dense[0])
+
+	s.SetCoordinates([][]int{{2, 0}, {0, 3}})
+	assert.Equal(t, []int{3, 8}, s.Sparse())
+
+	dense = make([]bool, 12)
+	dense[4] = true
+	dense[7] = true
+	s.SetDense(dense)
+	dense[0] = true
+	assert.Equal(t, []int{4, 7}, s.Sparse())
+	assert.Equal(t, [][]int{{1, 1}, {0, 3}}, s.Coordinates())
+
+	s.Zero()
+	assert.Equal(t, 0, s.NumActive())
+	assert.False(t, s.Dense()[4])
+}
+
+func TestSDRSetAlgebra(t *testing.T) {
+	a := NewSDRFromSparse([]int{1, 2, 3, 7}, 10)
+	b := NewSDRFromSparse([]int{2, 3, 5, 9}, 10)
+
+	assert.Equal(t, 2, a.Overlap(b))
+	assert.Equal(t, []int{1, 2, 3, 5, 7, 9}, a.Union(b).Sparse())
+	assert.Equal(t, []int{2, 3}, a.Intersection(b).Sparse())
+	assert.Equal(t, []int{1, 7}, a.Difference(b).Sparse())
+	assert.Equal(t, []int{5, 9}, b.Difference(a).Sparse())
+	assert.Equal(t, 0.4, a.Sparsity())
+}
+
+func TestSDREqual(t *testing.T) {
+	a := NewSDRFromSparse([]int{1, 4}, 2, 3)
+	b := NewSDRFromDense([]bool{false, true, false, false, true, false}, 2, 3)
+	assert.True(t, a.Equal(b))
+	assert.True(t, a.Equal(a.Copy()))
+
+	assert.False(t, a.Equal(NewSDRFromSparse([]int{1, 4}, 6)))
+	assert.False(t, a.Equal(NewSDRFromSparse([]int{1, 5}, 2, 3)))
+}
+
+func TestSDRSubsample(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	s := NewSDRFromSparse([]int{0, 10, 20, 30, 40, 50, 60, 70, 80, 90}, 100)
+
+	sub := s.Subsample(0.5, rng)
+	assert.Equal(t, 5, sub.NumActive())
+	assert.Equal(t, 5, sub.Overlap(s))
+	assert.Equal(t, 10, s.NumActive())
+
+	assert.True(t, s.Equal(s.Subsample(1.0, rng)))
+	assert.Equal(t, 0, s.Subsample(0, rng).NumActive())
+}
+
+func TestSDRAddNoise(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	s := NewSDRFromSparse([]int{0, 10, 20, 30, 40, 50, 60, 70, 80, 90}, 100)
+
+	noisy := s.AddNoise(0.3, rng)
+	assert.Equal(t, 10, noisy.NumActive())
+	assert.Equal(t, 7, noisy.Overlap(s))
+
+	assert.True(t, s.Equal(s.AddNoise(0, rng)))
+	assert.Equal(t, 0, s.AddNoise(1.0, rng).Overlap(s))
+}
+
+func TestSDRCompute(t *testing.T) {
+	spParams := NewSpParams()
+	spParams.InputDimensions = []int{100}
+	spParams.ColumnDimensions = []int{10, 20}
+	spParams.NumActiveColumnsPerInhArea = 4
+	spParams.GlobalInhibition = true
+	sp := NewSpatialPooler(spParams)
+
+	input := NewSDRFromSparse([]int{1, 5, 20, 30, 31, 32, 60, 99}, 100)
+	active := sp.ComputeSDR(input, true)
+	assert.Equal(t, []int{10, 20}, active.Dimensions())
+	assert.Equal(t, 4, active.NumActive())
+
+	tmParams := NewTemporalMemoryParams()
+	tmParams.ColumnDimensions = []int{10, 20}
+	tmParams.CellsPerColumn = 4
+	tm := NewTemporalMemory(tmParams)
+
+	cells := tm.ComputeSDR(active, true)
+	assert.Equal(t, []int{10, 20, 4}, cells.Dimensions())
+	assert.Equal(t, len(tm.ActiveCells), cells.NumActive())
+	for _, cell := range cells.Sparse() {
+		assert.True(t, utils.ContainsInt(cell/4, active.Sparse()))
+	}
+	assert.Equal(t, []int{10, 20, 4}, tm.PredictiveCellsSDR().Dimensions())
+}
@@ -298,7 +298,7 @@ activeSynapses. These synapses are randomly chosen from the set of cells
 that have learnState = true at timeStep.
 */
 func (tp *TemporalPooler) getSegmentActiveSynapses(c int, i int, s *Segment,
-	activeState *SparseBinaryMatrix, newSynapses bool) *SegmentUpdate {
+	activeState BinaryMatrix, newSynapses bool) *SegmentUpdate {
 	var activeSynapses []SynapseUpdateState
 
 	if tp.params.Verbosity >= 5 {
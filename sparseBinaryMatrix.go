@@ -94,6 +94,11 @@ func NewSparseBinaryMatrixFromInts(values [][]int) *SparseBinaryMatrix {
 // func (sm *SparseBinaryMatrix) Resize(width int, height int) {
 // }
 
+//Returns the number of rows and columns
+func (sm *SparseBinaryMatrix) Dims() (height int, width int) {
+	return sm.Height, sm.Width
+}
+
 //Returns all true/on indices
 func (sm *SparseBinaryMatrix) Entries() []SparseEntry {
 	return sm.entries
@@ -260,7 +265,12 @@ func (sm *SparseBinaryMatrix) TotalNonZeroCount() int {
 }
 
 // Ors 2 matrices
-func (sm *SparseBinaryMatrix) Or(sm2 *SparseBinaryMatrix) *SparseBinaryMatrix {
+func (sm *SparseBinaryMatrix) Or(other BinaryMatrix) BinaryMatrix {
+	sm2, ok := other.(*SparseBinaryMatrix)
+	if !ok {
+		return orBinaryMatrix(sm, other)
+	}
+
 	result := NewSparseBinaryMatrix(sm.Height, sm.Width)
 
 	for _, val := range sm.entries {
@@ -287,7 +297,7 @@ func (sm *SparseBinaryMatrix) FillRow(row int, val bool) {
 }
 
 //Copys a matrix
-func (sm *SparseBinaryMatrix) Copy() *SparseBinaryMatrix {
+func (sm *SparseBinaryMatrix) Copy() BinaryMatrix {
 	if sm == nil {
 		return nil
 	}
@@ -29,7 +29,13 @@ type SpatialPooler struct {
 	MinPctActiveDutyCycles     float64
 	DutyCyclePeriod            int
 	MaxBoost                   float64
+	BoostMode                  BoostMode
+	BoostStrength              float64
 	SpVerbosity                int
+	NumWorkers                 int
+	WrapAround                 bool
+	//Picks the active columns, DefaultInhibitor is used when nil
+	Inhibitor Inhibitor
 
 	// Extra parameter settings
 	SynPermMin           float64
@@ -44,13 +50,15 @@ type SpatialPooler struct {
 	IterationLearnNum int
 
 	//random seed
-	Seed int
+	Seed      int
+	rng       *rand.Rand
+	rngSource *utils.RandSource
 
-	potentialPools *DenseBinaryMatrix
+	potentialPools BinaryMatrix
 	permanences    *matrix.SparseMatrix
 	tieBreaker     []float64
 
-	connectedSynapses *DenseBinaryMatrix
+	connectedSynapses BinaryMatrix
 	//redundant
 	connectedCounts []int
 
@@ -61,6 +69,8 @@ type SpatialPooler struct {
 	boostFactors         []float64
 
 	inhibitionRadius int
+	//nil until local inhibition is first used
+	neighborhoods *columnNeighborhoods
 
 	spVerbosity int
 }
@@ -83,6 +93,21 @@ type SpParams struct {
 	MaxBoost                   float64
 	Seed                       int
 	SpVerbosity                int
+	//How boost factors are derived from the active duty cycles, MaxBoost
+	//only applies to LinearBoost
+	BoostMode BoostMode
+	//Steepness of the exponential boost modes
+	BoostStrength float64
+	//Number of goroutines used to compute overlaps and to update
+	//permanences and duty cycles, values below 2 run serially. Results
+	//do not depend on the number of workers.
+	NumWorkers int
+	//Whether inhibition neighborhoods wrap around the borders of the
+	//column space
+	WrapAround bool
+	//Creates the potential pool and connected synapse matrices,
+	//defaults to dense matrices when nil
+	MatrixFactory BinaryMatrixFactory
 }
 
 //Initializes default spatial pooler params
@@ -104,8 +129,12 @@ func NewSpParams() SpParams {
 	sp.MinPctActiveDutyCycle = 0.001
 	sp.DutyCyclePeriod = 1000
 	sp.MaxBoost = 10.0
+	sp.BoostMode = LinearBoost
+	sp.BoostStrength = 1.0
 	sp.Seed = -1
 	sp.SpVerbosity = 0
+	sp.NumWorkers = 1
+	sp.WrapAround = false
 
 	return sp
 }
@@ -114,19 +143,12 @@ func NewSpParams() SpParams {
 func NewSpatialPooler(spParams SpParams) *SpatialPooler {
 	sp := SpatialPooler{}
 	//Validate inputs
+	if err := spParams.Validate(); err != nil {
+		panic(err)
+	}
 	sp.numColumns = utils.ProdInt(spParams.ColumnDimensions)
 	sp.numInputs = utils.ProdInt(spParams.InputDimensions)
 
-	if sp.numColumns < 1 {
-		panic("Must have at least 1 column")
-	}
-	if sp.numInputs < 1 {
-		panic("must have at least 1 input")
-	}
-	if spParams.NumActiveColumnsPerInhArea < 1 && (spParams.LocalAreaDensity < 1) && (spParams.LocalAreaDensity >= 0.5) {
-		panic("Num active colums invalid")
-	}
-
 	sp.InputDimensions = spParams.InputDimensions
 	sp.ColumnDimensions = spParams.ColumnDimensions
 	sp.PotentialRadius = int(mathutil.Min(spParams.PotentialRadius, sp.numInputs))
@@ -143,16 +165,20 @@ func NewSpatialPooler(spParams SpParams) *SpatialPooler {
 	sp.MinPctActiveDutyCycles = spParams.MinPctActiveDutyCycle
 	sp.DutyCyclePeriod = spParams.DutyCyclePeriod
 	sp.MaxBoost = spParams.MaxBoost
+	sp.BoostMode = spParams.BoostMode
+	sp.BoostStrength = spParams.BoostStrength
 	sp.Seed = spParams.Seed
 	sp.SpVerbosity = spParams.SpVerbosity
+	sp.NumWorkers = spParams.NumWorkers
+	sp.WrapAround = spParams.WrapAround
+	sp.Inhibitor = DefaultInhibitor{}
+	sp.rngSource = utils.NewRandSource(int64(sp.Seed))
+	sp.rng = rand.New(sp.rngSource)
 
 	// Extra parameter settings
 	sp.SynPermMin = 0
 	sp.SynPermMax = 1
 	sp.SynPermTrimThreshold = sp.SynPermActiveInc / 2.0
-	if sp.SynPermTrimThreshold >= sp.SynPermConnected {
-		panic("Syn perm threshold >= syn connected.")
-	}
 	sp.UpdatePeriod = 50
 	sp.InitConnectedPct = 0.5
 
@@ -177,7 +203,11 @@ func NewSpatialPooler(spParams SpParams) *SpatialPooler {
 		     class, to reduce memory footprint and compuation time of algorithms that
 		     require iterating over the data strcuture.
 	*/
-	sp.potentialPools = NewDenseBinaryMatrix(sp.numColumns, sp.numInputs)
+	newMatrix := spParams.MatrixFactory
+	if newMatrix == nil {
+		newMatrix = DenseMatrixFactory
+	}
+	sp.potentialPools = newMatrix(sp.numColumns, sp.numInputs)
 
 	/*
 			 Initialize the permanences for each column. Similar to the
@@ -201,7 +231,7 @@ func NewSpatialPooler(spParams SpParams) *SpatialPooler {
 
 	sp.tieBreaker = make([]float64, sp.numColumns)
 	for i := 0; i < len(sp.tieBreaker); i++ {
-		sp.tieBreaker[i] = 0.01 * rand.Float64()
+		sp.tieBreaker[i] = 0.01 * sp.rng.Float64()
 	}
 
 	/*
@@ -212,7 +242,7 @@ func NewSpatialPooler(spParams SpParams) *SpatialPooler {
 		     this information is readily available from the 'permanence' matrix,
 		     it is stored separately for efficiency purposes.
 	*/
-	sp.connectedSynapses = NewDenseBinaryMatrix(sp.numColumns, sp.numInputs)
+	sp.connectedSynapses = newMatrix(sp.numColumns, sp.numInputs)
 
 	/*
 			 Stores the number of connected synapses for each column. This is simply
@@ -271,6 +301,43 @@ func (sp *SpatialPooler) NumColumns() int {
 	return sp.numColumns
 }
 
+//Validates params, returns a *ParamError naming the first invalid field
+func (ssp *SpParams) Validate() error {
+	if ssp.NumColumns() < 1 {
+		return &ParamError{Field: "ColumnDimensions", Reason: "must have at least 1 column"}
+	}
+	if ssp.NumInputs() < 1 {
+		return &ParamError{Field: "InputDimensions", Reason: "must have at least 1 input"}
+	}
+	if ssp.NumActiveColumnsPerInhArea < 1 &&
+		(ssp.LocalAreaDensity <= 0 || ssp.LocalAreaDensity > 0.5) {
+		return &ParamError{Field: "NumActiveColumnsPerInhArea",
+			Reason: "must be greater than 0 unless LocalAreaDensity is in (0, 0.5]"}
+	}
+	if ssp.SynPermActiveInc/2.0 >= ssp.SynPermConnected {
+		return &ParamError{Field: "SynPermActiveInc", Reason: "half of it must be less than SynPermConnected"}
+	}
+	if ssp.BoostMode < LinearBoost || ssp.BoostMode > NoBoost {
+		return &ParamError{Field: "BoostMode", Reason: "unknown boost mode"}
+	}
+	if ssp.BoostStrength < 0 {
+		return &ParamError{Field: "BoostStrength", Reason: "must not be negative"}
+	}
+	if ssp.NumWorkers < 0 {
+		return &ParamError{Field: "NumWorkers", Reason: "must not be negative"}
+	}
+	return nil
+}
+
+//Returns an error if the input vector does not match the number of inputs
+func (sp *SpatialPooler) ValidateInput(inputVector []bool) error {
+	if len(inputVector) != sp.numInputs {
+		return &ParamError{Field: "inputVector",
+			Reason: fmt.Sprintf("length %v does not match number of inputs %v", len(inputVector), sp.numInputs)}
+	}
+	return nil
+}
+
 //Returns number of inputs
 func (ssp *SpParams) NumInputs() int {
 	return utils.ProdInt(ssp.InputDimensions)
@@ -339,7 +406,7 @@ func (sp *SpatialPooler) mapPotential(index int, wrapAround bool) []bool {
 
 	//shuffle indices
 	for i := range indices {
-		j := rand.Intn(i + 1)
+		j := sp.rng.Intn(i + 1)
 		indices[i], indices[j] = indices[j], indices[i]
 	}
 
@@ -366,7 +433,7 @@ permanence values, but the history for this particular scheme has been lost.
 
 func (sp *SpatialPooler) initPermConnected() float64 {
 
-	p := sp.SynPermConnected + rand.Float64()*sp.SynPermActiveInc/4.0
+	p := sp.SynPermConnected + sp.rng.Float64()*sp.SynPermActiveInc/4.0
 
 	// Ensure we don't have too much unnecessary precision. A full 64 bits of
 	// precision causes numerical stability issues across platforms and across
@@ -381,7 +448,7 @@ func (sp *SpatialPooler) initPermConnected() float64 {
 */
 
 func (sp *SpatialPooler) initPermNonConnected() float64 {
-	p := sp.SynPermConnected * rand.Float64()
+	p := sp.SynPermConnected * sp.rng.Float64()
 
 	// Ensure we don't have too much unnecessary precision. A full 64 bits of
 	// precision causes numerical stability issues across platforms and across
@@ -419,7 +486,7 @@ func (sp *SpatialPooler) initPermanence(potential []bool, connectedPct float64)
 			continue
 		}
 		var temp float64
-		if utils.RandFloatRange(0.0, 1.0) < connectedPct {
+		if sp.rng.Float64() < connectedPct {
 			temp = sp.initPermConnected()
 		} else {
 			temp = sp.initPermNonConnected()
@@ -508,6 +575,16 @@ assignment is required.
 */
 
 func (sp *SpatialPooler) updatePermanencesForColumn(perm []float64, index int, raisePerm bool) {
+	newConnected := sp.clipPermanencesForColumn(perm, index, raisePerm)
+	sp.setPermanencesForColumn(perm, index, newConnected)
+}
+
+/*
+	Raises and clips the permanences of a column in place, returns the
+indices of its connected synapses. Only reads shared state so columns
+can be processed concurrently.
+*/
+func (sp *SpatialPooler) clipPermanencesForColumn(perm []float64, index int, raisePerm bool) []int {
 	maskPotential := sp.potentialPools.GetRowIndices(index)
 	if raisePerm {
 		sp.raisePermanenceToThreshold(perm, maskPotential)
@@ -532,10 +609,17 @@ func (sp *SpatialPooler) updatePermanencesForColumn(perm []float64, index int, r
 		}
 	}
 
+	return newConnected
+}
+
+//Stores the clipped permanences and connected synapses of a column
+func (sp *SpatialPooler) setPermanencesForColumn(perm []float64, index int, newConnected []int) {
 	//TODO: replace with sparse matrix that indexes by rows
 	//sp.permanences.SetRowFromDense(index, perm)
 	for i := 0; i < len(perm); i++ {
-		sp.permanences.Set(index, i, perm[i])
+		if sp.permanences.Get(index, i) != perm[i] {
+			sp.permanences.Set(index, i, perm[i])
+		}
 	}
 	sp.connectedSynapses.ReplaceRowByIndices(index, newConnected)
 	sp.connectedCounts[index] = len(newConnected)
@@ -564,28 +648,28 @@ learn: a boolean value indicating whether learning should be
 	   of the model. Setting learning to 'off' freezes the SP
 	   and has many uses. For example, you might want to feed in
 	   various inputs and examine the resulting SDR's.
-activeArray: an array whose size is equal to the number of columns.
-	   Before the function returns this array will be populated
-       with 1's at the indices of the active columns, and 0's
-	   everywhere else.
+
+Returns a dense array with an entry for every column that is true for the
+active columns, and the sorted indices of the active columns.
 */
-func (sp *SpatialPooler) Compute(inputVector []bool, learn bool, activeArray []bool, inhibitColumns inhibitColFunc) {
-	if len(inputVector) != sp.numInputs {
-		panic("input != numimputs")
+func (sp *SpatialPooler) Compute(inputVector []bool, learn bool) (activeArray []bool, activeColumns []int) {
+	if err := sp.ValidateInput(inputVector); err != nil {
+		panic(err)
 	}
 
 	sp.updateBookeepingVars(learn)
 	overlaps := sp.calculateOverlap(inputVector)
 	boostedOverlaps := make([]float64, len(overlaps))
 	// Apply boosting when learning is on
-	if learn {
-		for i, val := range sp.boostFactors {
-			boostedOverlaps[i] = float64(overlaps[i]) * val
+	for i, val := range overlaps {
+		boostedOverlaps[i] = float64(val)
+		if learn {
+			boostedOverlaps[i] *= sp.boostFactors[i]
 		}
 	}
 
 	// Apply inhibition to determine the winning columns
-	activeColumns := inhibitColumns(boostedOverlaps, sp.inhibitColumnsGlobal, sp.inhibitColumnsLocal)
+	activeColumns = sp.InhibitColumns(boostedOverlaps)
 	overlapsf := make([]float64, len(overlaps))
 	for i, val := range overlaps {
 		overlapsf[i] = float64(val)
@@ -605,12 +689,22 @@ func (sp *SpatialPooler) Compute(inputVector []bool, learn bool, activeArray []b
 		activeColumns = sp.stripNeverLearned(activeColumns)
 	}
 
-	if len(activeColumns) > 0 {
-		for i, _ := range activeArray {
-			activeArray[i] = utils.ContainsInt(i, activeColumns)
-		}
+	activeArray = make([]bool, sp.numColumns)
+	for _, col := range activeColumns {
+		activeArray[col] = true
 	}
 
+	return activeArray, activeColumns
+}
+
+/*
+	Computes the active columns for an SDR input, see Compute. The input
+must have as many bits as the pooler has inputs, the returned SDR has the
+column dimensions.
+*/
+func (sp *SpatialPooler) ComputeSDR(input *SDR, learn bool) *SDR {
+	_, activeColumns := sp.Compute(input.Dense(), learn)
+	return NewSDRFromSparse(activeColumns, sp.ColumnDimensions...)
 }
 
 /*
@@ -647,7 +741,22 @@ inputVector: a numpy array of 0's and 1's that comprises the input to
 the spatial pooler.
 */
 func (sp *SpatialPooler) calculateOverlap(inputVector []bool) []int {
-	overlaps := sp.connectedSynapses.RowAndSum(inputVector)
+	var overlaps []int
+	if sp.NumWorkers < 2 {
+		overlaps = sp.connectedSynapses.RowAndSum(inputVector)
+	} else {
+		overlaps = make([]int, sp.numColumns)
+		sp.parallelRange(sp.numColumns, func(start, end int) {
+			for col := start; col < end; col++ {
+				for _, idx := range sp.connectedSynapses.GetRowIndices(col) {
+					if inputVector[idx] {
+						overlaps[col]++
+					}
+				}
+			}
+		})
+	}
+
 	for idx, _ := range overlaps {
 		if overlaps[idx] < sp.StimulusThreshold {
 			overlaps[idx] = 0
@@ -803,9 +912,10 @@ density: The fraction of columns to survive inhibition. This
 func (sp *SpatialPooler) inhibitColumnsLocal(overlaps []float64, density float64) []int {
 	var activeColumns []int
 	addToWinners := utils.MaxSliceFloat64(overlaps) / 1000.0
+	neighborhoods := sp.columnNeighborhoods()
 
 	for i := 0; i < sp.numColumns; i++ {
-		mask := sp.getNeighborsND(i, sp.ColumnDimensions, sp.inhibitionRadius, false)
+		mask := neighborhoods.neighbors(i)
 
 		ovSlice := make([]float64, len(mask))
 		for idx, val := range mask {
@@ -829,13 +939,10 @@ func (sp *SpatialPooler) inhibitColumnsLocal(overlaps []float64, density float64
 	return activeColumns
 }
 
-type inhibitColumnsFunc func([]float64, float64) []int
-type inhibitColFunc func(overlaps []float64, inhibitColumnsGlobal, inhibitColumnsLocal inhibitColumnsFunc) []int
-
 /*
  Performs inhibition. This method calculates the necessary values needed to
 actually perform inhibition and then delegates the task of picking the
-active columns to helper functions.
+active columns to the Inhibitor of the pooler.
 
 Parameters:
 ----------------------------
@@ -846,21 +953,8 @@ that are connected to input bits which are turned on.
 
 */
 
-func (sp *SpatialPooler) InhibitColumns(overlaps []float64, inhibitColumnsGlobal, inhibitColumnsLocal inhibitColumnsFunc) []int {
-	/*
-			 determine how many columns should be selected in the inhibition phase.
-		     This can be specified by either setting the 'numActiveColumnsPerInhArea'
-		     parameter of the 'localAreaDensity' parameter when initializing the class
-	*/
-	density := 0.0
-	if sp.LocalAreaDensity > 0 {
-		density = sp.LocalAreaDensity
-	} else {
-		inhibitionArea := math.Pow(float64(2*sp.inhibitionRadius+1), float64(len(sp.ColumnDimensions)))
-		inhibitionArea = math.Min(float64(sp.numColumns), inhibitionArea)
-		density = float64(sp.NumActiveColumnsPerInhArea) / inhibitionArea
-		density = math.Min(density, 0.5)
-	}
+func (sp *SpatialPooler) InhibitColumns(overlaps []float64) []int {
+	density := sp.inhibitionDensity()
 
 	// Add our fixed little bit of random noise to the scores to help break ties.
 	//overlaps += sp.tieBreaker
@@ -868,13 +962,33 @@ func (sp *SpatialPooler) InhibitColumns(overlaps []float64, inhibitColumnsGlobal
 		overlaps[i] += sp.tieBreaker[i]
 	}
 
-	if sp.GlobalInhibition ||
-		sp.inhibitionRadius > utils.MaxSliceInt(sp.ColumnDimensions) {
-		return inhibitColumnsGlobal(overlaps, density)
-	} else {
-		return inhibitColumnsLocal(overlaps, density)
+	inhibitor := sp.Inhibitor
+	if inhibitor == nil {
+		inhibitor = DefaultInhibitor{}
+	}
+	return inhibitor.Inhibit(sp, overlaps, density)
+}
+
+/*
+ Returns the fraction of columns that should be selected in an inhibition
+area. This can be specified by either setting the 'numActiveColumnsPerInhArea'
+parameter or the 'localAreaDensity' parameter when initializing the class.
+*/
+func (sp *SpatialPooler) inhibitionDensity() float64 {
+	if sp.LocalAreaDensity > 0 {
+		return sp.LocalAreaDensity
 	}
+	inhibitionArea := math.Pow(float64(2*sp.inhibitionRadius+1), float64(len(sp.ColumnDimensions)))
+	inhibitionArea = math.Min(float64(sp.numColumns), inhibitionArea)
+	density := float64(sp.NumActiveColumnsPerInhArea) / inhibitionArea
+	return math.Min(density, 0.5)
+}
 
+//Returns true if global inhibition was requested or the inhibition radius
+//spans all columns
+func (sp *SpatialPooler) inhibitsGlobally() bool {
+	return sp.GlobalInhibition ||
+		sp.inhibitionRadius > utils.MaxSliceInt(sp.ColumnDimensions)
 }
 
 /*
@@ -906,18 +1020,30 @@ func (sp *SpatialPooler) adaptSynapses(inputVector []bool, activeColumns []int)
 		permChanges[val] = sp.SynPermActiveInc
 	}
 
-	for _, ac := range activeColumns {
-		perm := make([]float64, sp.numInputs)
-		mask := sp.potentialPools.GetRowIndices(ac)
-		for j := 0; j < sp.numInputs; j++ {
-			if utils.ContainsInt(j, mask) {
-				perm[j] = permChanges[j] + sp.permanences.Get(ac, j)
-			} else {
-				perm[j] = sp.permanences.Get(ac, j)
-			}
+	//new permanences are computed concurrently, the matrices are
+	//not safe for concurrent writes so they are updated afterwards
+	perms := make([][]float64, len(activeColumns))
+	connected := make([][]int, len(activeColumns))
+	sp.parallelRange(len(activeColumns), func(start, end int) {
+		for i := start; i < end; i++ {
+			ac := activeColumns[i]
+			perm := make([]float64, sp.numInputs)
+			mask := sp.potentialPools.GetRowIndices(ac)
+			for j := 0; j < sp.numInputs; j++ {
+				if utils.ContainsInt(j, mask) {
+					perm[j] = permChanges[j] + sp.permanences.Get(ac, j)
+				} else {
+					perm[j] = sp.permanences.Get(ac, j)
+				}
 
+			}
+			perms[i] = perm
+			connected[i] = sp.clipPermanencesForColumn(perm, ac, true)
 		}
-		sp.updatePermanencesForColumn(perm, ac, true)
+	})
+
+	for i, ac := range activeColumns {
+		sp.setPermanencesForColumn(perms[i], ac, connected[i])
 	}
 
 }
@@ -958,17 +1084,24 @@ func (sp *SpatialPooler) updateDutyCycles(overlaps []float64, activeColumns []in
 		period = sp.IterationNum
 	}
 
-	sp.overlapDutyCycles = updateDutyCyclesHelper(
-		sp.overlapDutyCycles,
-		overlapArray,
-		period,
-	)
-
-	sp.activeDutyCycles = updateDutyCyclesHelper(
-		sp.activeDutyCycles,
-		activeArray,
-		period,
-	)
+	overlapDutyCycles := make([]float64, sp.numColumns)
+	activeDutyCycles := make([]float64, sp.numColumns)
+	sp.parallelRange(sp.numColumns, func(start, end int) {
+		copy(overlapDutyCycles[start:end], updateDutyCyclesHelper(
+			sp.overlapDutyCycles[start:end],
+			overlapArray[start:end],
+			period,
+		))
+
+		copy(activeDutyCycles[start:end], updateDutyCyclesHelper(
+			sp.activeDutyCycles[start:end],
+			activeArray[start:end],
+			period,
+		))
+	})
+
+	sp.overlapDutyCycles = overlapDutyCycles
+	sp.activeDutyCycles = activeDutyCycles
 }
 
 /*
@@ -985,17 +1118,27 @@ func (sp *SpatialPooler) bumpUpWeakColumns() {
 		}
 	}
 
-	for _, col := range weakColumns {
-		perm := make([]float64, sp.numInputs)
-		for j := 0; j < sp.numInputs; j++ {
-			perm[j] = sp.permanences.Get(col, j)
-		}
+	perms := make([][]float64, len(weakColumns))
+	connected := make([][]int, len(weakColumns))
+	sp.parallelRange(len(weakColumns), func(start, end int) {
+		for i := start; i < end; i++ {
+			col := weakColumns[i]
+			perm := make([]float64, sp.numInputs)
+			for j := 0; j < sp.numInputs; j++ {
+				perm[j] = sp.permanences.Get(col, j)
+			}
 
-		maskPotential := sp.potentialPools.GetRowIndices(col)
-		for _, mpot := range maskPotential {
-			perm[mpot] += sp.SynPermBelowStimulusInc
+			maskPotential := sp.potentialPools.GetRowIndices(col)
+			for _, mpot := range maskPotential {
+				perm[mpot] += sp.SynPermBelowStimulusInc
+			}
+			perms[i] = perm
+			connected[i] = sp.clipPermanencesForColumn(perm, col, false)
 		}
-		sp.updatePermanencesForColumn(perm, col, false)
+	})
+
+	for i, col := range weakColumns {
+		sp.setPermanencesForColumn(perms[i], col, connected[i])
 	}
 
 }
@@ -1026,6 +1169,20 @@ minActiveDutyCycle
 */
 
 func (sp *SpatialPooler) updateBoostFactors() {
+	switch sp.BoostMode {
+	case GlobalExponentialBoost:
+		sp.updateBoostFactorsExponential(sp.globalTargetDensities())
+	case LocalExponentialBoost:
+		sp.updateBoostFactorsExponential(sp.localTargetDensities())
+	case NoBoost:
+		utils.FillSliceFloat64(sp.boostFactors, 1.0)
+	default:
+		sp.updateBoostFactorsLinear()
+	}
+}
+
+//Legacy linear boosting, see updateBoostFactors
+func (sp *SpatialPooler) updateBoostFactorsLinear() {
 	for i, val := range sp.minActiveDutyCycles {
 		if val > 0 {
 			sp.boostFactors[i] = ((1.0 - sp.MaxBoost) /
@@ -1165,6 +1322,7 @@ func (sp *SpatialPooler) updateInhibitionRadius(avgConnectedSpanForColumnND avgC
 	if sp.GlobalInhibition {
 		cmax := utils.MaxSliceInt(sp.ColumnDimensions)
 		sp.inhibitionRadius = cmax
+		sp.neighborhoods = nil
 		return
 	}
 
@@ -1179,7 +1337,17 @@ func (sp *SpatialPooler) updateInhibitionRadius(avgConnectedSpanForColumnND avgC
 	radius := (diameter - 1) / 2.0
 	radius = math.Max(1.0, radius)
 
-	sp.inhibitionRadius = int(utils.RoundPrec(radius, 0))
+	sp.setInhibitionRadius(int(utils.RoundPrec(radius, 0)))
+}
+
+//Sets the inhibition radius, the neighborhoods are only rebuilt when it
+//changes
+func (sp *SpatialPooler) setInhibitionRadius(radius int) {
+	if radius == sp.inhibitionRadius && sp.neighborhoods != nil {
+		return
+	}
+	sp.inhibitionRadius = radius
+	sp.neighborhoods = newColumnNeighborhoods(sp.ColumnDimensions, radius, sp.WrapAround)
 }
 
 /*
@@ -1190,7 +1358,7 @@ func (sp *SpatialPooler) updateMinDutyCycles() {
 	if sp.GlobalInhibition || sp.inhibitionRadius > sp.numInputs {
 		sp.updateMinDutyCyclesGlobal()
 	} else {
-		sp.updateMinDutyCyclesLocal(sp.getNeighborsND)
+		sp.updateMinDutyCyclesLocal(sp.neighborsND)
 	}
 
 }
@@ -1222,7 +1390,7 @@ different columns.
 func (sp *SpatialPooler) updateMinDutyCyclesLocal(getNeighborsND getNeighborsNDFunc) {
 
 	for i := 0; i < sp.numColumns; i++ {
-		maskNeighbors := getNeighborsND(i, sp.ColumnDimensions, sp.inhibitionRadius, false)
+		maskNeighbors := getNeighborsND(i, sp.ColumnDimensions, sp.inhibitionRadius, sp.WrapAround)
 		maskNeighbors = append(maskNeighbors, i)
 
 		maxOverlap := utils.MaxSliceFloat64(utils.SubsetSliceFloat64(sp.overlapDutyCycles, maskNeighbors))
@@ -0,0 +1,68 @@
+package htm
+
+import (
+	"math"
+)
+
+//Strategy used to compute the boost factors of a spatial pooler
+type BoostMode int
+
+const (
+	/*
+		Legacy boosting, columns whose active duty cycle falls below their
+	minimum duty cycle are boosted linearly up to MaxBoost
+	*/
+	LinearBoost BoostMode = iota
+	/*
+		boost = exp(-BoostStrength * (activeDutyCycle - targetDensity))
+	with the inhibition density of the pooler as target for every column
+	*/
+	GlobalExponentialBoost
+	/*
+		Exponential boost with the mean active duty cycle of the column's
+	inhibition neighborhood as target density
+	*/
+	LocalExponentialBoost
+	//Boost factors stay at 1
+	NoBoost
+)
+
+/*
+	Sets the boost factor of every column to
+exp(-BoostStrength * (activeDutyCycle - targetDensity)). Columns less
+active than their target are boosted above 1, more active columns are
+suppressed below 1.
+*/
+func (sp *SpatialPooler) updateBoostFactorsExponential(targetDensities []float64) {
+	for i, dutyCycle := range sp.activeDutyCycles {
+		sp.boostFactors[i] = math.Exp(-sp.BoostStrength * (dutyCycle - targetDensities[i]))
+	}
+}
+
+//Returns the inhibition density as target density of every column
+func (sp *SpatialPooler) globalTargetDensities() []float64 {
+	density := sp.inhibitionDensity()
+	result := make([]float64, sp.numColumns)
+	for i := range result {
+		result[i] = density
+	}
+	return result
+}
+
+/*
+	Returns the mean active duty cycle of the inhibition neighborhood of
+every column, including the column itself
+*/
+func (sp *SpatialPooler) localTargetDensities() []float64 {
+	neighborhoods := sp.columnNeighborhoods()
+	result := make([]float64, sp.numColumns)
+	for i := range result {
+		sum := sp.activeDutyCycles[i]
+		neighbors := neighborhoods.neighbors(i)
+		for _, n := range neighbors {
+			sum += sp.activeDutyCycles[n]
+		}
+		result[i] = sum / float64(len(neighbors)+1)
+	}
+	return result
+}
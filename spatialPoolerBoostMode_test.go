@@ -0,0 +1,176 @@
+package htm
+
+import (
+	"github.com/nupic-community/htm/utils"
+	"github.com/zacg/testify/assert"
+	"math"
+	"testing"
+)
+
+/*
+ Runs the setup of spatialPoolerBoost_test.go with the specified boost
+mode: 5 input patterns, 600 columns with 10% output sparsity and no
+permanence changes, so winners only change because of boosting. Returns
+the pooler and the number of times each column won.
+*/
+func runBoostModeTest(mode BoostMode, strength float64, batches int) (*SpatialPooler, []int) {
+	spParams := NewSpParams()
+	spParams.InputDimensions = []int{90}
+	spParams.ColumnDimensions = []int{600}
+	spParams.PotentialRadius = 90
+	spParams.PotentialPct = 0.9
+	spParams.GlobalInhibition = true
+	spParams.NumActiveColumnsPerInhArea = 60
+	spParams.MinPctActiveDutyCycle = 0.1
+	spParams.SynPermActiveInc = 0
+	spParams.SynPermInactiveDec = 0
+	spParams.DutyCyclePeriod = 10
+	spParams.BoostMode = mode
+	spParams.BoostStrength = strength
+	spParams.Seed = 42
+	sp := NewSpatialPooler(spParams)
+
+	x := make([][]bool, 5)
+	for i := range x {
+		x[i] = make([]bool, sp.numInputs)
+	}
+	//x[0] and x[1] overlap by half, the others do not overlap at all
+	for i, start := range []int{0, 10, 30, 50, 70} {
+		utils.FillSliceRangeBool(x[i], true, start, 20)
+	}
+
+	wins := make([]int, sp.numColumns)
+	for i := 0; i < batches; i++ {
+		for _, input := range x {
+			_, active := sp.Compute(input, true)
+			for _, col := range active {
+				wins[col]++
+			}
+		}
+	}
+
+	return sp, wins
+}
+
+func TestUpdateBoostFactorsGlobalExponential(t *testing.T) {
+	sp := SpatialPooler{}
+	sp.BoostMode = GlobalExponentialBoost
+	sp.BoostStrength = 10
+	sp.numColumns = 6
+	sp.ColumnDimensions = []int{6}
+	sp.LocalAreaDensity = 0.1
+	sp.activeDutyCycles = []float64{0.1, 0.3, 0.02, 0.04, 0.7, 0.12}
+	sp.boostFactors = make([]float64, sp.numColumns)
+	sp.updateBoostFactors()
+
+	for i, dutyCycle := range sp.activeDutyCycles {
+		assert.InDelta(t, math.Exp(-10*(dutyCycle-0.1)), sp.boostFactors[i], 1e-9)
+	}
+	assert.Equal(t, 1.0, sp.boostFactors[0])
+	assert.True(t, sp.boostFactors[2] > 1)
+	assert.True(t, sp.boostFactors[4] < 1)
+
+	//the target follows NumActiveColumnsPerInhArea without a local area density
+	sp.LocalAreaDensity = -1
+	sp.NumActiveColumnsPerInhArea = 3
+	sp.inhibitionRadius = 6
+	sp.updateBoostFactors()
+	assert.InDelta(t, math.Exp(-10*(0.1-0.5)), sp.boostFactors[0], 1e-9)
+}
+
+func TestUpdateBoostFactorsLocalExponential(t *testing.T) {
+	sp := SpatialPooler{}
+	sp.BoostMode = LocalExponentialBoost
+	sp.BoostStrength = 10
+	sp.numColumns = 6
+	sp.ColumnDimensions = []int{6}
+	sp.inhibitionRadius = 1
+	sp.activeDutyCycles = []float64{0.1, 0.3, 0.02, 0.04, 0.7, 0.12}
+	sp.boostFactors = make([]float64, sp.numColumns)
+	sp.updateBoostFactors()
+
+	//mean duty cycle of each column and its direct neighbors
+	targets := []float64{0.2, 0.14, 0.12, 0.76 / 3, 0.86 / 3, 0.41}
+	for i, dutyCycle := range sp.activeDutyCycles {
+		assert.InDelta(t, math.Exp(-10*(dutyCycle-targets[i])), sp.boostFactors[i], 1e-9)
+	}
+
+	//with wrap around the first and last columns are neighbors
+	sp.WrapAround = true
+	sp.updateBoostFactors()
+	assert.InDelta(t, math.Exp(-10*(0.1-0.52/3)), sp.boostFactors[0], 1e-9)
+	assert.InDelta(t, math.Exp(-10*(0.12-0.92/3)), sp.boostFactors[5], 1e-9)
+}
+
+func TestUpdateBoostFactorsNoBoost(t *testing.T) {
+	sp := SpatialPooler{}
+	sp.BoostMode = NoBoost
+	sp.MaxBoost = 10
+	sp.numColumns = 6
+	sp.minActiveDutyCycles = []float64{0.1, 0.1, 0.1, 0.1, 0.1, 0.1}
+	sp.activeDutyCycles = []float64{0, 0.3, 0.02, 0.04, 0.7, 0.12}
+	sp.boostFactors = []float64{1, 2, 3, 4, 5, 6}
+	sp.updateBoostFactors()
+	assert.Equal(t, []float64{1, 1, 1, 1, 1, 1}, sp.boostFactors)
+}
+
+func TestLinearBoostMode(t *testing.T) {
+	//the minimum duty cycles are only calculated after 50 iterations, so
+	//boosting has no effect during the first 10 batches
+	sp, wins := runBoostModeTest(LinearBoost, 0, 10)
+	assert.Equal(t, sp.numColumns, utils.CountFloat64(sp.boostFactors, 1))
+	assert.True(t, utils.CountInt(wins, 0) > sp.numColumns/3, "Expected many columns to have never won")
+
+	//afterwards every column gets boosted enough to win
+	_, wins = runBoostModeTest(LinearBoost, 0, 20)
+	assert.Equal(t, 0, utils.CountInt(wins, 0), "Expected all columns to have won atleast once.")
+}
+
+func TestExponentialBoostModes(t *testing.T) {
+	for _, mode := range []BoostMode{GlobalExponentialBoost, LocalExponentialBoost} {
+		//columns that never won are boosted by exp(strength * target density)
+		sp, wins := runBoostModeTest(mode, 10, 2)
+		for col, count := range wins {
+			if count == 0 {
+				assert.InDelta(t, math.E, sp.boostFactors[col], 1e-6)
+			}
+		}
+
+		//boosting starts right away, so every column wins quickly
+		sp, wins = runBoostModeTest(mode, 10, 10)
+		assert.Equal(t, 0, utils.CountInt(wins, 0), "Expected all columns to have won atleast once.")
+		for col, dutyCycle := range sp.activeDutyCycles {
+			if dutyCycle > 0.1 {
+				assert.True(t, sp.boostFactors[col] < 1, "Columns above the target density should be suppressed")
+			}
+		}
+	}
+}
+
+func TestNoBoostMode(t *testing.T) {
+	_, winsBefore := runBoostModeTest(NoBoost, 0, 2)
+	sp, wins := runBoostModeTest(NoBoost, 0, 20)
+
+	//without boosting and permanence changes the winners never change
+	assert.Equal(t, sp.numColumns, utils.CountFloat64(sp.boostFactors, 1))
+	assert.True(t, utils.CountInt(wins, 0) > sp.numColumns/3, "Expected many columns to have never won")
+	for col, count := range wins {
+		assert.Equal(t, winsBefore[col]*10, count)
+	}
+}
+
+func TestBoostModeValidation(t *testing.T) {
+	p := NewSpParams()
+	assert.Nil(t, p.Validate())
+
+	p.BoostMode = NoBoost + 1
+	err, ok := p.Validate().(*ParamError)
+	assert.True(t, ok)
+	assert.Equal(t, "BoostMode", err.Field)
+
+	p.BoostMode = GlobalExponentialBoost
+	p.BoostStrength = -1
+	err, ok = p.Validate().(*ParamError)
+	assert.True(t, ok)
+	assert.Equal(t, "BoostStrength", err.Field)
+}
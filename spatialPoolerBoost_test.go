@@ -120,11 +120,9 @@ func verifySDRProps(t *testing.T, bt *boostTest) {
 }
 
 func phase1(t *testing.T, bt *boostTest) {
-	y := make([]bool, bt.sp.numColumns)
 	// Do one training batch through the input patterns
 	for idx, input := range bt.x {
-		utils.FillSliceBool(y, false)
-		bt.sp.Compute(input, true, y, bt.sp.InhibitColumns)
+		y, _ := bt.sp.Compute(input, true)
 		for j, winner := range y {
 			if winner {
 				bt.winningIteration[j] = bt.sp.IterationLearnNum
@@ -166,13 +164,10 @@ func phase1(t *testing.T, bt *boostTest) {
 
 func phase2(t *testing.T, bt *boostTest) {
 
-	y := make([]bool, bt.sp.numColumns)
-
 	// Do 9 training batch through the input patterns
 	for i := 0; i < 9; i++ {
 		for idx, input := range bt.x {
-			utils.FillSliceBool(y, false)
-			bt.sp.Compute(input, true, y, bt.sp.InhibitColumns)
+			y, _ := bt.sp.Compute(input, true)
 			for j, winner := range y {
 				if winner {
 					bt.winningIteration[j] = bt.sp.IterationLearnNum
@@ -218,12 +213,11 @@ func phase2(t *testing.T, bt *boostTest) {
 
 func phase3(t *testing.T, bt *boostTest) {
 	//Do two more training batches through the input patterns
-	y := make([]bool, bt.sp.numColumns)
+	var y []bool
 
 	for i := 0; i < 2; i++ {
 		for idx, input := range bt.x {
-			utils.FillSliceBool(y, false)
-			bt.sp.Compute(input, true, y, bt.sp.InhibitColumns)
+			y, _ = bt.sp.Compute(input, true)
 			for j, winner := range y {
 				if winner {
 					bt.winningIteration[j] = bt.sp.IterationLearnNum
@@ -263,10 +257,8 @@ func phase4(t *testing.T, bt *boostTest) {
 	copy(bt.sp.boostFactors, boostAtBeg)
 
 	// Do one more iteration through the input patterns with learning OFF
-	y := make([]bool, bt.sp.numColumns)
 	for _, input := range bt.x {
-		utils.FillSliceBool(y, false)
-		bt.sp.Compute(input, false, y, bt.sp.InhibitColumns)
+		bt.sp.Compute(input, false)
 
 		// The boost factor for all columns that just won should be at 1.
 		assert.Equal(t, utils.SumSliceFloat64(boostAtBeg), utils.SumSliceFloat64(bt.sp.boostFactors), "Boost factors changed when learning is off")
@@ -35,17 +35,15 @@ func basicComputeLoop(t *testing.T, spParams SpParams) {
 	}
 
 	// With learning off and no prior training we should get no winners
-	y := make([]bool, sp.numColumns)
+	var y []bool
 	for _, input := range inputMatrix {
-		utils.FillSliceBool(y, false)
-		sp.Compute(input, false, y, sp.InhibitColumns)
+		y, _ = sp.Compute(input, false)
 		assert.Equal(t, 0, utils.CountTrue(y))
 	}
 
 	// With learning on we should get the requested number of winners
 	for _, input := range inputMatrix {
-		utils.FillSliceBool(y, false)
-		sp.Compute(input, true, y, sp.InhibitColumns)
+		y, _ = sp.Compute(input, true)
 		assert.Equal(t, sp.NumActiveColumnsPerInhArea, utils.CountTrue(y))
 
 	}
@@ -53,8 +51,7 @@ func basicComputeLoop(t *testing.T, spParams SpParams) {
 	// With learning off and some prior training we should get the requested
 	// number of winners
 	for _, input := range inputMatrix {
-		utils.FillSliceBool(y, false)
-		sp.Compute(input, false, y, sp.InhibitColumns)
+		y, _ = sp.Compute(input, false)
 		assert.Equal(t, sp.NumActiveColumnsPerInhArea, utils.CountTrue(y))
 	}
 
@@ -82,3 +79,73 @@ func TestBasicCompute2(t *testing.T) {
 	basicComputeLoop(t, spParams)
 
 }
+
+func TestComputeSameSeedSameResult(t *testing.T) {
+	/*
+		Two poolers with the same seed should produce identical output
+		regardless of other poolers drawing random numbers in between.
+	*/
+	spParams := NewSpParams()
+	spParams.InputDimensions = []int{30}
+	spParams.ColumnDimensions = []int{50}
+	spParams.GlobalInhibition = true
+	spParams.Seed = 7
+
+	otherParams := spParams
+	otherParams.Seed = 3
+
+	sp1 := NewSpatialPooler(spParams)
+	other := NewSpatialPooler(otherParams)
+	sp2 := NewSpatialPooler(spParams)
+
+	assert.Equal(t, sp1.tieBreaker, sp2.tieBreaker)
+	assert.Equal(t, sp1.potentialPools.Flatten(), sp2.potentialPools.Flatten())
+
+	for i := 0; i < 20; i++ {
+		input := make([]bool, sp1.numInputs)
+		for j := range input {
+			input[j] = rand.Float64() > 0.8
+		}
+		y1, _ := sp1.Compute(input, true)
+		other.mapPotential(0, true)
+		y2, _ := sp2.Compute(input, true)
+		assert.Equal(t, y1, y2)
+	}
+}
+
+func TestComputeLearnOffUsesRawOverlaps(t *testing.T) {
+	spParams := NewSpParams()
+	spParams.InputDimensions = []int{50}
+	spParams.ColumnDimensions = []int{20}
+	spParams.PotentialRadius = 50
+	spParams.GlobalInhibition = true
+	spParams.NumActiveColumnsPerInhArea = 3
+	sp := NewSpatialPooler(spParams)
+
+	input := make([]bool, sp.numInputs)
+	for i := range input {
+		input[i] = i%3 == 0
+	}
+	overlaps := sp.calculateOverlap(input)
+
+	//every column has learned, the weakest one is boosted far above the rest
+	weakest := 0
+	for i := range sp.activeDutyCycles {
+		sp.activeDutyCycles[i] = 0.1
+		if overlaps[i] < overlaps[weakest] {
+			weakest = i
+		}
+	}
+	sp.boostFactors[weakest] = 1000
+
+	rawOverlaps := make([]float64, len(overlaps))
+	for i, val := range overlaps {
+		rawOverlaps[i] = float64(val)
+	}
+	expected := sp.InhibitColumns(rawOverlaps)
+	assert.Equal(t, sp.NumActiveColumnsPerInhArea, len(expected))
+
+	_, activeColumns := sp.Compute(input, false)
+	assert.Equal(t, expected, activeColumns)
+	assert.False(t, utils.ContainsInt(weakest, activeColumns), "Boost must not apply when learning is off")
+}
@@ -0,0 +1,72 @@
+package htm
+
+/*
+ An inhibitor picks the active columns of a spatial pooler from the
+boosted overlap scores of all its columns. density is the target fraction
+of active columns within an inhibition area. overlaps already include the
+tie breaker and may be modified. Returns the sorted indices of the winning
+columns.
+*/
+type Inhibitor interface {
+	Inhibit(sp *SpatialPooler, overlaps []float64, density float64) []int
+}
+
+//Adapts an ordinary function to the Inhibitor interface
+type InhibitorFunc func(sp *SpatialPooler, overlaps []float64, density float64) []int
+
+func (f InhibitorFunc) Inhibit(sp *SpatialPooler, overlaps []float64, density float64) []int {
+	return f(sp, overlaps, density)
+}
+
+/*
+ Picks the columns with the highest overlaps of the whole pooler, the
+number of winners is density times the number of columns.
+*/
+type GlobalInhibitor struct{}
+
+func (GlobalInhibitor) Inhibit(sp *SpatialPooler, overlaps []float64, density float64) []int {
+	return sp.inhibitColumnsGlobal(overlaps, density)
+}
+
+/*
+ Picks every column whose overlap is within the top density fraction of
+its neighborhood, the neighborhood extends inhibitionRadius columns in
+every dimension and wraps around the borders with WrapAround.
+*/
+type LocalInhibitor struct{}
+
+func (LocalInhibitor) Inhibit(sp *SpatialPooler, overlaps []float64, density float64) []int {
+	return sp.inhibitColumnsLocal(overlaps, density)
+}
+
+/*
+ Picks every column whose overlap is within the top density fraction of
+its neighborhood like LocalInhibitor, but decides all columns
+independently by sliding the neighborhood window across the column space.
+Much faster on large column spaces, ties are not resolved in favor of
+earlier winners.
+*/
+type SlidingWindowInhibitor struct{}
+
+func (SlidingWindowInhibitor) Inhibit(sp *SpatialPooler, overlaps []float64, density float64) []int {
+	return sp.inhibitColumnsSlidingWindow(overlaps, density)
+}
+
+/*
+ Inhibits globally when GlobalInhibition is set or the inhibition radius
+spans all columns, locally otherwise. Used when a pooler has no Inhibitor.
+*/
+type DefaultInhibitor struct{}
+
+func (DefaultInhibitor) Inhibit(sp *SpatialPooler, overlaps []float64, density float64) []int {
+	if sp.inhibitsGlobally() {
+		return GlobalInhibitor{}.Inhibit(sp, overlaps, density)
+	}
+	return LocalInhibitor{}.Inhibit(sp, overlaps, density)
+}
+
+var _ Inhibitor = InhibitorFunc(nil)
+var _ Inhibitor = GlobalInhibitor{}
+var _ Inhibitor = LocalInhibitor{}
+var _ Inhibitor = SlidingWindowInhibitor{}
+var _ Inhibitor = DefaultInhibitor{}
@@ -0,0 +1,92 @@
+package htm
+
+import (
+	"github.com/nupic-community/htm/utils"
+	"github.com/zacg/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+func newInhibitorTestPooler(global bool) *SpatialPooler {
+	spParams := NewSpParams()
+	spParams.InputDimensions = []int{10, 10}
+	spParams.ColumnDimensions = []int{10, 10}
+	spParams.PotentialRadius = 3
+	spParams.NumActiveColumnsPerInhArea = 4
+	spParams.GlobalInhibition = global
+	spParams.Seed = 42
+	return NewSpatialPooler(spParams)
+}
+
+func randomInput(rng *rand.Rand, size int) []bool {
+	input := make([]bool, size)
+	for i := range input {
+		input[i] = rng.Float64() > 0.8
+	}
+	return input
+}
+
+func TestComputeReturnsDenseAndSparse(t *testing.T) {
+	sp := newInhibitorTestPooler(true)
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 10; i++ {
+		activeArray, activeColumns := sp.Compute(randomInput(rng, sp.numInputs), true)
+		assert.Equal(t, sp.numColumns, len(activeArray))
+		assert.Equal(t, 4, len(activeColumns))
+		for col, active := range activeArray {
+			assert.Equal(t, utils.ContainsInt(col, activeColumns), active)
+		}
+	}
+}
+
+func TestDefaultInhibitorDispatch(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	input := randomInput(rng, 100)
+
+	//an explicit inhibitor matches the default choice
+	global := newInhibitorTestPooler(true)
+	global2 := newInhibitorTestPooler(true)
+	global2.Inhibitor = GlobalInhibitor{}
+	local := newInhibitorTestPooler(false)
+	local.inhibitionRadius = 2
+	local2 := newInhibitorTestPooler(false)
+	local2.inhibitionRadius = 2
+	local2.Inhibitor = LocalInhibitor{}
+
+	for i := 0; i < 10; i++ {
+		_, y1 := global.Compute(input, true)
+		_, y2 := global2.Compute(input, true)
+		assert.Equal(t, y1, y2)
+
+		_, y1 = local.Compute(input, true)
+		_, y2 = local2.Compute(input, true)
+		assert.Equal(t, y1, y2)
+	}
+
+	//a nil inhibitor uses the default
+	sp := newInhibitorTestPooler(true)
+	sp2 := newInhibitorTestPooler(true)
+	sp2.Inhibitor = nil
+	_, y1 := sp.Compute(input, true)
+	_, y2 := sp2.Compute(input, true)
+	assert.Equal(t, y1, y2)
+}
+
+func TestUserDefinedInhibitor(t *testing.T) {
+	sp := newInhibitorTestPooler(true)
+
+	calls := 0
+	sp.Inhibitor = InhibitorFunc(func(sp *SpatialPooler, overlaps []float64, density float64) []int {
+		calls++
+		assert.Equal(t, sp.numColumns, len(overlaps))
+		assert.Equal(t, 0.04, density)
+		return []int{3, 50}
+	})
+
+	activeArray, activeColumns := sp.Compute(make([]bool, sp.numInputs), true)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, []int{3, 50}, activeColumns)
+	assert.True(t, activeArray[3])
+	assert.True(t, activeArray[50])
+}
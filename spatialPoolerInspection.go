@@ -0,0 +1,160 @@
+package htm
+
+import (
+	"fmt"
+)
+
+//Panics if index is not a valid column index
+func (sp *SpatialPooler) checkColumnIndex(index int) {
+	if index < 0 || index >= sp.numColumns {
+		panic(&ParamError{Field: "columnIndex",
+			Reason: fmt.Sprintf("%v out of range [0, %v)", index, sp.numColumns)})
+	}
+}
+
+//Panics if a per column slice does not have an entry for every column
+func (sp *SpatialPooler) checkColumnValues(field string, length int) {
+	if length != sp.numColumns {
+		panic(&ParamError{Field: field,
+			Reason: fmt.Sprintf("length %v does not match number of columns %v", length, sp.numColumns)})
+	}
+}
+
+//Panics if a per input slice does not have an entry for every input
+func (sp *SpatialPooler) checkInputValues(field string, length int) {
+	if length != sp.numInputs {
+		panic(&ParamError{Field: field,
+			Reason: fmt.Sprintf("length %v does not match number of inputs %v", length, sp.numInputs)})
+	}
+}
+
+//Returns a copy of values
+func copyFloat64s(values []float64) []float64 {
+	result := make([]float64, len(values))
+	copy(result, values)
+	return result
+}
+
+//Returns the permanence of every input of a column, 0 outside of its
+//potential pool
+func (sp *SpatialPooler) GetPermanence(columnIndex int) []float64 {
+	sp.checkColumnIndex(columnIndex)
+	result := make([]float64, sp.numInputs)
+	for i := range result {
+		result[i] = sp.permanences.Get(columnIndex, i)
+	}
+	return result
+}
+
+/*
+	Sets the permanences of a column. Permanences are trimmed and clipped
+like during learning and the connected synapses of the column are updated,
+permanences are not raised to reach the stimulus threshold.
+*/
+func (sp *SpatialPooler) SetPermanence(columnIndex int, permanence []float64) {
+	sp.checkColumnIndex(columnIndex)
+	sp.checkInputValues("permanence", len(permanence))
+	perm := copyFloat64s(permanence)
+	sp.updatePermanencesForColumn(perm, columnIndex, false)
+}
+
+//Returns the potential pool of a column, true for inputs the column
+//may connect to
+func (sp *SpatialPooler) GetPotential(columnIndex int) []bool {
+	sp.checkColumnIndex(columnIndex)
+	return sp.potentialPools.GetDenseRow(columnIndex)
+}
+
+/*
+	Sets the potential pool of a column. Existing permanences are kept,
+learning only adapts permanences inside the potential pool.
+*/
+func (sp *SpatialPooler) SetPotential(columnIndex int, potential []bool) {
+	sp.checkColumnIndex(columnIndex)
+	sp.checkInputValues("potential", len(potential))
+	sp.potentialPools.ReplaceRow(columnIndex, potential)
+}
+
+//Returns the connected synapses of a column, true for inputs whose
+//permanence is at least SynPermConnected
+func (sp *SpatialPooler) GetConnectedSynapses(columnIndex int) []bool {
+	sp.checkColumnIndex(columnIndex)
+	return sp.connectedSynapses.GetDenseRow(columnIndex)
+}
+
+//Returns the number of connected synapses of every column
+func (sp *SpatialPooler) GetConnectedCounts() []int {
+	result := make([]int, len(sp.connectedCounts))
+	copy(result, sp.connectedCounts)
+	return result
+}
+
+//Returns the boost factor of every column
+func (sp *SpatialPooler) GetBoostFactors() []float64 {
+	return copyFloat64s(sp.boostFactors)
+}
+
+//Sets the boost factor of every column, they are recalculated by the
+//next learning iteration unless BoostMode is NoBoost
+func (sp *SpatialPooler) SetBoostFactors(boostFactors []float64) {
+	sp.checkColumnValues("boostFactors", len(boostFactors))
+	copy(sp.boostFactors, boostFactors)
+}
+
+//Returns the active duty cycle of every column
+func (sp *SpatialPooler) GetActiveDutyCycles() []float64 {
+	return copyFloat64s(sp.activeDutyCycles)
+}
+
+//Sets the active duty cycle of every column
+func (sp *SpatialPooler) SetActiveDutyCycles(dutyCycles []float64) {
+	sp.checkColumnValues("activeDutyCycles", len(dutyCycles))
+	copy(sp.activeDutyCycles, dutyCycles)
+}
+
+//Returns the overlap duty cycle of every column
+func (sp *SpatialPooler) GetOverlapDutyCycles() []float64 {
+	return copyFloat64s(sp.overlapDutyCycles)
+}
+
+//Sets the overlap duty cycle of every column
+func (sp *SpatialPooler) SetOverlapDutyCycles(dutyCycles []float64) {
+	sp.checkColumnValues("overlapDutyCycles", len(dutyCycles))
+	copy(sp.overlapDutyCycles, dutyCycles)
+}
+
+//Returns the minimum active duty cycle of every column
+func (sp *SpatialPooler) GetMinActiveDutyCycles() []float64 {
+	return copyFloat64s(sp.minActiveDutyCycles)
+}
+
+//Sets the minimum active duty cycle of every column
+func (sp *SpatialPooler) SetMinActiveDutyCycles(dutyCycles []float64) {
+	sp.checkColumnValues("minActiveDutyCycles", len(dutyCycles))
+	copy(sp.minActiveDutyCycles, dutyCycles)
+}
+
+//Returns the minimum overlap duty cycle of every column
+func (sp *SpatialPooler) GetMinOverlapDutyCycles() []float64 {
+	return copyFloat64s(sp.minOverlapDutyCycles)
+}
+
+//Sets the minimum overlap duty cycle of every column
+func (sp *SpatialPooler) SetMinOverlapDutyCycles(dutyCycles []float64) {
+	sp.checkColumnValues("minOverlapDutyCycles", len(dutyCycles))
+	copy(sp.minOverlapDutyCycles, dutyCycles)
+}
+
+//Returns the radius of the local inhibition neighborhoods
+func (sp *SpatialPooler) GetInhibitionRadius() int {
+	return sp.inhibitionRadius
+}
+
+//Sets the radius of the local inhibition neighborhoods, it is
+//recalculated by the next learning update round
+func (sp *SpatialPooler) SetInhibitionRadius(radius int) {
+	if radius < 0 {
+		panic(&ParamError{Field: "radius", Reason: "must not be negative"})
+	}
+	sp.setInhibitionRadius(radius)
+}
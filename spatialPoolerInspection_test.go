@@ -0,0 +1,113 @@
+package htm
+
+import (
+	"github.com/zacg/testify/assert"
+	"testing"
+)
+
+func newInspectionTestSP() *SpatialPooler {
+	spParams := NewSpParams()
+	spParams.InputDimensions = []int{10}
+	spParams.ColumnDimensions = []int{5}
+	spParams.PotentialRadius = 10
+	spParams.PotentialPct = 1
+	spParams.GlobalInhibition = true
+	spParams.NumActiveColumnsPerInhArea = 2
+	return NewSpatialPooler(spParams)
+}
+
+func TestSpatialPoolerGetters(t *testing.T) {
+	sp := newInspectionTestSP()
+
+	for col := 0; col < sp.numColumns; col++ {
+		perm := sp.GetPermanence(col)
+		assert.Equal(t, sp.numInputs, len(perm))
+		connected := sp.GetConnectedSynapses(col)
+		count := 0
+		for i, p := range perm {
+			assert.Equal(t, sp.permanences.Get(col, i), p)
+			assert.Equal(t, p >= sp.SynPermConnected, connected[i])
+			if connected[i] {
+				count++
+			}
+		}
+		assert.Equal(t, count, sp.GetConnectedCounts()[col])
+		assert.Equal(t, sp.potentialPools.GetDenseRow(col), sp.GetPotential(col))
+	}
+
+	assert.Equal(t, sp.boostFactors, sp.GetBoostFactors())
+	assert.Equal(t, sp.activeDutyCycles, sp.GetActiveDutyCycles())
+	assert.Equal(t, sp.overlapDutyCycles, sp.GetOverlapDutyCycles())
+	assert.Equal(t, sp.inhibitionRadius, sp.GetInhibitionRadius())
+
+	//getters return copies
+	sp.GetBoostFactors()[0] = 5
+	sp.GetConnectedCounts()[0] = -1
+	assert.Equal(t, 1.0, sp.boostFactors[0])
+	assert.True(t, sp.connectedCounts[0] >= 0)
+}
+
+func TestSpatialPoolerSetPermanence(t *testing.T) {
+	sp := newInspectionTestSP()
+
+	perm := []float64{0.5, 0, 0.01, 0.09, 0.1, 0.11, 1.2, 0, 0, 0.3}
+	sp.SetPermanence(2, perm)
+
+	//trimmed and clipped, but not raised
+	assert.Equal(t, []float64{0.5, 0, 0, 0.09, 0.1, 0.11, 1, 0, 0, 0.3}, sp.GetPermanence(2))
+	assert.Equal(t, []bool{true, false, false, false, true, true, true, false, false, true},
+		sp.GetConnectedSynapses(2))
+	assert.Equal(t, 5, sp.GetConnectedCounts()[2])
+	//the argument is not modified
+	assert.Equal(t, 1.2, perm[6])
+}
+
+func TestSpatialPoolerSetters(t *testing.T) {
+	sp := newInspectionTestSP()
+
+	potential := []bool{true, true, false, false, false, false, false, false, true, true}
+	sp.SetPotential(1, potential)
+	assert.Equal(t, potential, sp.GetPotential(1))
+
+	values := []float64{0.1, 0.2, 0.3, 0.4, 0.5}
+	sp.SetBoostFactors(values)
+	assert.Equal(t, values, sp.GetBoostFactors())
+	sp.SetActiveDutyCycles(values)
+	assert.Equal(t, values, sp.GetActiveDutyCycles())
+	sp.SetOverlapDutyCycles(values)
+	assert.Equal(t, values, sp.GetOverlapDutyCycles())
+	sp.SetMinActiveDutyCycles(values)
+	assert.Equal(t, values, sp.GetMinActiveDutyCycles())
+	sp.SetMinOverlapDutyCycles(values)
+	assert.Equal(t, values, sp.GetMinOverlapDutyCycles())
+
+	//setters copy their argument
+	values[0] = 9
+	assert.Equal(t, 0.1, sp.GetBoostFactors()[0])
+
+	sp.SetInhibitionRadius(2)
+	assert.Equal(t, 2, sp.GetInhibitionRadius())
+	assert.True(t, sp.columnNeighborhoods().matches(sp.ColumnDimensions, 2, sp.WrapAround))
+}
+
+func TestSpatialPoolerSettersValidate(t *testing.T) {
+	sp := newInspectionTestSP()
+
+	expectParamError := func(field string, fn func()) {
+		defer func() {
+			err, ok := recover().(*ParamError)
+			assert.True(t, ok, "Expected a *ParamError panic")
+			if ok {
+				assert.Equal(t, field, err.Field)
+			}
+		}()
+		fn()
+	}
+
+	expectParamError("columnIndex", func() { sp.GetPermanence(5) })
+	expectParamError("columnIndex", func() { sp.GetPotential(-1) })
+	expectParamError("permanence", func() { sp.SetPermanence(0, make([]float64, 3)) })
+	expectParamError("potential", func() { sp.SetPotential(0, make([]bool, 11)) })
+	expectParamError("boostFactors", func() { sp.SetBoostFactors(make([]float64, 4)) })
+	expectParamError("radius", func() { sp.SetInhibitionRadius(-1) })
+}
@@ -0,0 +1,296 @@
+package htm
+
+import (
+	"sort"
+)
+
+/*
+ Precomputed inhibition neighborhoods of the columns of a spatial pooler.
+Neighborhoods are hyper rectangles, so instead of a neighbor list per
+column only the coordinates within radius of every coordinate are stored
+for each dimension. Rebuilt whenever the inhibition radius changes.
+*/
+type columnNeighborhoods struct {
+	dimensions []int
+	radius     int
+	wrapAround bool
+	//dimension -> coordinate -> coordinates within radius, including itself
+	ranges [][][]int
+	//flat index stride of each dimension
+	strides []int
+}
+
+/*
+	Intializes the neighborhoods of a column space with the specified
+dimensions, coordinates within radius are neighbors. With wrapAround the
+first and last coordinate of every dimension are adjacent.
+*/
+func newColumnNeighborhoods(dimensions []int, radius int, wrapAround bool) *columnNeighborhoods {
+	if len(dimensions) < 1 {
+		panic("Dimensions empty")
+	}
+
+	cn := new(columnNeighborhoods)
+	cn.dimensions = make([]int, len(dimensions))
+	copy(cn.dimensions, dimensions)
+	cn.radius = radius
+	cn.wrapAround = wrapAround
+
+	cn.strides = make([]int, len(dimensions))
+	stride := 1
+	for d := len(dimensions) - 1; d >= 0; d-- {
+		cn.strides[d] = stride
+		stride *= dimensions[d]
+	}
+
+	cn.ranges = make([][][]int, len(dimensions))
+	for d, dim := range dimensions {
+		cn.ranges[d] = make([][]int, dim)
+		for coord := 0; coord < dim; coord++ {
+			cn.ranges[d][coord] = neighborhoodRange(coord, dim, radius, wrapAround)
+		}
+	}
+
+	return cn
+}
+
+//Returns the coordinates within radius of coord in a dimension of size dim
+func neighborhoodRange(coord, dim, radius int, wrapAround bool) []int {
+	var result []int
+	if wrapAround && 2*radius+1 >= dim {
+		result = make([]int, dim)
+		for i := range result {
+			result[i] = i
+		}
+		return result
+	}
+
+	result = make([]int, 0, 2*radius+1)
+	for i := coord - radius; i <= coord+radius; i++ {
+		switch {
+		case wrapAround:
+			result = append(result, (i+dim)%dim)
+		case i >= 0 && i < dim:
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+//Returns true if the neighborhoods were built for the specified topology
+func (cn *columnNeighborhoods) matches(dimensions []int, radius int, wrapAround bool) bool {
+	if cn.radius != radius || cn.wrapAround != wrapAround || len(cn.dimensions) != len(dimensions) {
+		return false
+	}
+	for d, dim := range dimensions {
+		if cn.dimensions[d] != dim {
+			return false
+		}
+	}
+	return true
+}
+
+//Returns the coordinates of a flat column index
+func (cn *columnNeighborhoods) coordinates(columnIndex int) []int {
+	coords := make([]int, len(cn.dimensions))
+	for d, stride := range cn.strides {
+		coords[d] = (columnIndex / stride) % cn.dimensions[d]
+	}
+	return coords
+}
+
+//Returns the number of columns in the neighborhood of a column, including
+//the column itself
+func (cn *columnNeighborhoods) size(columnIndex int) int {
+	size := 1
+	for d, coord := range cn.coordinates(columnIndex) {
+		size *= len(cn.ranges[d][coord])
+	}
+	return size
+}
+
+/*
+	Returns the flat indices of the neighbors of a column, excluding the
+column itself. Contains the same columns as getNeighborsND.
+*/
+func (cn *columnNeighborhoods) neighbors(columnIndex int) []int {
+	coords := cn.coordinates(columnIndex)
+	result := make([]int, 0, cn.size(columnIndex))
+	cn.eachInBox(coords, len(cn.dimensions), 0, func(idx int) {
+		if idx != columnIndex {
+			result = append(result, idx)
+		}
+	})
+	return result
+}
+
+/*
+	Calls fn with the flat index of every column in the neighborhood of
+coords over the first numDims dimensions, base is added to every index
+*/
+func (cn *columnNeighborhoods) eachInBox(coords []int, numDims int, base int, fn func(int)) {
+	if numDims == 0 {
+		fn(base)
+		return
+	}
+
+	var visit func(d int, offset int)
+	visit = func(d int, offset int) {
+		for _, c := range cn.ranges[d][coords[d]] {
+			idx := offset + c*cn.strides[d]
+			if d == numDims-1 {
+				fn(idx)
+			} else {
+				visit(d+1, idx)
+			}
+		}
+	}
+	visit(0, base)
+}
+
+/*
+	Returns the precomputed neighborhoods of the current column topology
+and inhibition radius, rebuilding them if either changed
+*/
+func (sp *SpatialPooler) columnNeighborhoods() *columnNeighborhoods {
+	if sp.neighborhoods == nil ||
+		!sp.neighborhoods.matches(sp.ColumnDimensions, sp.inhibitionRadius, sp.WrapAround) {
+		sp.neighborhoods = newColumnNeighborhoods(sp.ColumnDimensions, sp.inhibitionRadius, sp.WrapAround)
+	}
+	return sp.neighborhoods
+}
+
+/*
+	Same as getNeighborsND, answered from the precomputed neighborhoods
+when they match the requested topology
+*/
+func (sp *SpatialPooler) neighborsND(columnIndex int, dimensions []int, radius int, wrapAround bool) []int {
+	cn := sp.columnNeighborhoods()
+	if cn.matches(dimensions, radius, wrapAround) {
+		return cn.neighbors(columnIndex)
+	}
+	return sp.getNeighborsND(columnIndex, dimensions, radius, wrapAround)
+}
+
+//Counts values by rank, used to count the overlaps in a window that are
+//larger than a given overlap
+type fenwickTree []int
+
+func (ft fenwickTree) add(rank int, delta int) {
+	for i := rank + 1; i < len(ft); i += i & -i {
+		ft[i] += delta
+	}
+}
+
+//Returns the number of values with a rank <= rank
+func (ft fenwickTree) countUpTo(rank int) int {
+	count := 0
+	for i := rank + 1; i > 0; i -= i & -i {
+		count += ft[i]
+	}
+	return count
+}
+
+//Returns the rank of every overlap, equal overlaps share a rank
+func overlapRanks(overlaps []float64) (ranks []int, numRanks int) {
+	order := make([]int, len(overlaps))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return overlaps[order[i]] < overlaps[order[j]]
+	})
+
+	ranks = make([]int, len(overlaps))
+	for i, idx := range order {
+		if i > 0 && overlaps[idx] != overlaps[order[i-1]] {
+			numRanks++
+		}
+		ranks[idx] = numRanks
+	}
+	return ranks, numRanks + 1
+}
+
+/*
+ Performs local inhibition by sliding the neighborhood window along the
+last column dimension. A column wins if fewer than density times the size
+of its neighborhood columns in the neighborhood have a larger overlap.
+Unlike inhibitColumnsLocal earlier winners do not suppress later columns
+with equal overlap, which allows every column to be decided independently.
+
+Overlaps of the window are kept in a fenwick tree indexed by overlap rank.
+Moving the window by one column removes and adds one slab of columns, so
+the cost per column is the neighborhood size divided by its extent in the
+last dimension, times log of the number of columns.
+*/
+func (sp *SpatialPooler) inhibitColumnsSlidingWindow(overlaps []float64, density float64) []int {
+	cn := sp.columnNeighborhoods()
+	ranks, numRanks := overlapRanks(overlaps)
+	window := make(fenwickTree, numRanks+1)
+
+	lastDim := len(cn.dimensions) - 1
+	lineLength := cn.dimensions[lastDim]
+	numLines := sp.numColumns / lineLength
+	lastRanges := cn.ranges[lastDim]
+	fullLine := cn.wrapAround && 2*cn.radius+1 >= lineLength
+
+	var slab []int
+	//adds or removes the columns at coordinate c of the last dimension
+	update := func(c int, delta int) {
+		for _, base := range slab {
+			window.add(ranks[base+c], delta)
+		}
+	}
+
+	activeColumns := []int{}
+	for line := 0; line < numLines; line++ {
+		lineStart := line * lineLength
+		coords := cn.coordinates(lineStart)
+
+		//offsets of the neighboring lines in the other dimensions
+		slab = slab[:0]
+		cn.eachInBox(coords, lastDim, 0, func(idx int) {
+			slab = append(slab, idx)
+		})
+
+		for _, c := range lastRanges[0] {
+			update(c, 1)
+		}
+		inWindow := len(slab) * len(lastRanges[0])
+
+		for c := 0; c < lineLength; c++ {
+			if c > 0 && !fullLine {
+				leaving := c - 1 - cn.radius
+				entering := c + cn.radius
+				if cn.wrapAround {
+					leaving = (leaving + lineLength) % lineLength
+					entering = entering % lineLength
+					update(leaving, -1)
+					update(entering, 1)
+				} else {
+					if leaving >= 0 {
+						update(leaving, -1)
+					}
+					if entering < lineLength {
+						update(entering, 1)
+					}
+				}
+				inWindow = len(slab) * len(lastRanges[c])
+			}
+
+			col := lineStart + c
+			numBigger := inWindow - window.countUpTo(ranks[col])
+			numActive := int(0.5 + density*float64(inWindow))
+			if numBigger < numActive {
+				activeColumns = append(activeColumns, col)
+			}
+		}
+
+		//empty the window for the next line
+		for _, c := range lastRanges[lineLength-1] {
+			update(c, -1)
+		}
+	}
+
+	return activeColumns
+}
@@ -0,0 +1,145 @@
+package htm
+
+import (
+	"github.com/zacg/testify/assert"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+var neighborhoodTestDimensions = [][]int{{10}, {5, 7}, {3, 4, 5}}
+
+func TestColumnNeighborhoodsMatchGetNeighborsND(t *testing.T) {
+	sp := SpatialPooler{}
+	for _, dims := range neighborhoodTestDimensions {
+		numColumns := 1
+		for _, dim := range dims {
+			numColumns *= dim
+		}
+		for radius := 0; radius < 5; radius++ {
+			for _, wrap := range []bool{false, true} {
+				cn := newColumnNeighborhoods(dims, radius, wrap)
+				for col := 0; col < numColumns; col++ {
+					expected := append([]int{}, sp.getNeighborsND(col, dims, radius, wrap)...)
+					actual := cn.neighbors(col)
+					sort.Ints(expected)
+					sort.Ints(actual)
+					assert.Equal(t, expected, actual)
+					assert.Equal(t, len(expected)+1, cn.size(col))
+				}
+			}
+		}
+	}
+}
+
+//Reference implementation of sliding window inhibition
+func bruteForceKWinners(sp *SpatialPooler, overlaps []float64, density float64) []int {
+	result := []int{}
+	for col := range overlaps {
+		mask := sp.getNeighborsND(col, sp.ColumnDimensions, sp.inhibitionRadius, sp.WrapAround)
+		numBigger := 0
+		for _, n := range mask {
+			if overlaps[n] > overlaps[col] {
+				numBigger++
+			}
+		}
+		if numBigger < int(0.5+density*float64(len(mask)+1)) {
+			result = append(result, col)
+		}
+	}
+	return result
+}
+
+func TestSlidingWindowMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for _, dims := range neighborhoodTestDimensions {
+		sp := SpatialPooler{}
+		sp.ColumnDimensions = dims
+		sp.numColumns = 1
+		for _, dim := range dims {
+			sp.numColumns *= dim
+		}
+
+		for radius := 1; radius < 4; radius++ {
+			for _, wrap := range []bool{false, true} {
+				sp.inhibitionRadius = radius
+				sp.WrapAround = wrap
+				for _, density := range []float64{0.1, 0.3} {
+					//small integers to produce ties
+					overlaps := make([]float64, sp.numColumns)
+					for i := range overlaps {
+						overlaps[i] = float64(rng.Intn(6))
+					}
+					expected := bruteForceKWinners(&sp, overlaps, density)
+					actual := sp.inhibitColumnsSlidingWindow(overlaps, density)
+					assert.Equal(t, expected, actual)
+				}
+			}
+		}
+	}
+}
+
+func TestSlidingWindowInhibitor(t *testing.T) {
+	sp := SpatialPooler{}
+	sp.numColumns = 10
+	sp.ColumnDimensions = []int{sp.numColumns}
+	sp.inhibitionRadius = 2
+	overlaps := []float64{1, 2, 7, 0, 3, 4, 16, 1, 1.5, 1.7}
+	assert.Equal(t, []int{1, 2, 5, 6, 9}, SlidingWindowInhibitor{}.Inhibit(&sp, overlaps, 0.5))
+
+	//column 0 and 9 are neighbors with wrap around
+	sp.WrapAround = true
+	sp.inhibitionRadius = 1
+	overlaps = []float64{5, 1, 1, 1, 1, 1, 1, 1, 1, 4}
+	assert.Equal(t, []int{0, 2, 3, 4, 5, 6, 7}, SlidingWindowInhibitor{}.Inhibit(&sp, overlaps, 0.34))
+}
+
+func TestNeighborhoodsRebuiltOnRadiusChange(t *testing.T) {
+	spParams := NewSpParams()
+	spParams.InputDimensions = []int{20}
+	spParams.ColumnDimensions = []int{40}
+	spParams.PotentialRadius = 5
+	sp := NewSpatialPooler(spParams)
+
+	cn := sp.columnNeighborhoods()
+	assert.Equal(t, sp.inhibitionRadius, cn.radius)
+
+	sp.setInhibitionRadius(sp.inhibitionRadius)
+	assert.True(t, cn == sp.columnNeighborhoods())
+
+	sp.setInhibitionRadius(sp.inhibitionRadius + 1)
+	assert.True(t, cn != sp.neighborhoods)
+	assert.Equal(t, sp.inhibitionRadius, sp.neighborhoods.radius)
+
+	//topology changes made directly to the pooler are picked up too
+	sp.inhibitionRadius = 3
+	assert.Equal(t, 3, sp.columnNeighborhoods().radius)
+}
+
+func benchmarkLocalInhibition(b *testing.B, inhibitor Inhibitor) {
+	sp := SpatialPooler{}
+	sp.ColumnDimensions = []int{64, 32}
+	sp.numColumns = 2048
+	sp.inhibitionRadius = 8
+
+	rng := rand.New(rand.NewSource(1))
+	overlaps := make([]float64, sp.numColumns)
+	for i := range overlaps {
+		overlaps[i] = float64(rng.Intn(20)) + rng.Float64()*0.01
+	}
+	input := make([]float64, len(overlaps))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(input, overlaps)
+		inhibitor.Inhibit(&sp, input, 0.02)
+	}
+}
+
+func BenchmarkLocalInhibitor(b *testing.B) {
+	benchmarkLocalInhibition(b, LocalInhibitor{})
+}
+
+func BenchmarkSlidingWindowInhibitor(b *testing.B) {
+	benchmarkLocalInhibition(b, SlidingWindowInhibitor{})
+}
@@ -0,0 +1,37 @@
+package htm
+
+import (
+	"sync"
+)
+
+/*
+	Splits the range [0, n) into consecutive shards and calls fn for each
+of them on up to NumWorkers goroutines, returns once all shards are done.
+Runs fn(0, n) on the calling goroutine when NumWorkers is below 2. fn
+must only write state belonging to indices within its shard.
+*/
+func (sp *SpatialPooler) parallelRange(n int, fn func(start, end int)) {
+	workers := sp.NumWorkers
+	if workers > n {
+		workers = n
+	}
+	if workers < 2 {
+		fn(0, n)
+		return
+	}
+
+	var wg sync.WaitGroup
+	shardSize := (n + workers - 1) / workers
+	for start := 0; start < n; start += shardSize {
+		end := start + shardSize
+		if end > n {
+			end = n
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			fn(start, end)
+		}(start, end)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,99 @@
+package htm
+
+import (
+	"github.com/zacg/testify/assert"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestParallelRange(t *testing.T) {
+	sp := SpatialPooler{}
+	for _, workers := range []int{0, 1, 3, 8, 20} {
+		sp.NumWorkers = workers
+		var mu sync.Mutex
+		visited := make([]int, 10)
+		sp.parallelRange(10, func(start, end int) {
+			mu.Lock()
+			defer mu.Unlock()
+			for i := start; i < end; i++ {
+				visited[i]++
+			}
+		})
+		assert.Equal(t, []int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}, visited)
+	}
+
+	called := false
+	sp.NumWorkers = 4
+	sp.parallelRange(0, func(start, end int) {
+		called = true
+		assert.Equal(t, start, end)
+	})
+	assert.True(t, called)
+}
+
+func TestSpatialPoolerParallelMatchesSerial(t *testing.T) {
+	newPooler := func(workers int, global bool) *SpatialPooler {
+		spParams := NewSpParams()
+		spParams.InputDimensions = []int{16, 16}
+		spParams.ColumnDimensions = []int{16, 16}
+		spParams.PotentialRadius = 5
+		spParams.GlobalInhibition = global
+		spParams.Seed = 42
+		spParams.NumWorkers = workers
+		return NewSpatialPooler(spParams)
+	}
+
+	for _, global := range []bool{true, false} {
+		serial := newPooler(1, global)
+		parallel := newPooler(4, global)
+
+		rng := rand.New(rand.NewSource(7))
+		for i := 0; i < 60; i++ {
+			input := make([]bool, serial.numInputs)
+			for j := range input {
+				input[j] = rng.Float64() > 0.85
+			}
+			learn := i%5 != 4
+			active, _ := serial.Compute(input, learn)
+			active2, _ := parallel.Compute(input, learn)
+			assert.Equal(t, active, active2)
+		}
+
+		assert.Equal(t, serial.overlapDutyCycles, parallel.overlapDutyCycles)
+		assert.Equal(t, serial.activeDutyCycles, parallel.activeDutyCycles)
+		assert.Equal(t, serial.boostFactors, parallel.boostFactors)
+		assert.Equal(t, serial.connectedCounts, parallel.connectedCounts)
+		assert.Equal(t, serial.connectedSynapses.Flatten(), parallel.connectedSynapses.Flatten())
+		for i := 0; i < serial.numColumns; i++ {
+			assert.Equal(t, GetRowFromSM(serial.permanences, i), GetRowFromSM(parallel.permanences, i))
+		}
+	}
+}
+
+func benchmarkSpatialPoolerCompute(b *testing.B, workers int) {
+	spParams := NewSpParams()
+	spParams.Seed = 42
+	spParams.GlobalInhibition = true
+	spParams.NumWorkers = workers
+	sp := NewSpatialPooler(spParams)
+
+	rng := rand.New(rand.NewSource(7))
+	input := make([]bool, sp.numInputs)
+	for j := range input {
+		input[j] = rng.Float64() > 0.9
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sp.Compute(input, true)
+	}
+}
+
+func BenchmarkSpatialPoolerComputeSerial(b *testing.B) {
+	benchmarkSpatialPoolerCompute(b, 1)
+}
+
+func BenchmarkSpatialPoolerComputeParallel(b *testing.B) {
+	benchmarkSpatialPoolerCompute(b, 4)
+}
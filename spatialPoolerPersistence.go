@@ -0,0 +1,237 @@
+package htm
+
+import (
+	"encoding/gob"
+	"fmt"
+	"github.com/nupic-community/htm/utils"
+	"github.com/skelterjohn/go.matrix"
+	"io"
+	"math/rand"
+)
+
+//Version of the spatial pooler serialization format, bump when
+//spatialPoolerState changes in an incompatible way
+const spatialPoolerSerialVersion = 3
+
+/*
+Serializable snapshot of a spatial pooler. Contains the params the pooler
+was created with as well as all learned state, matrices are stored as
+per column index lists to keep the encoding compact.
+*/
+type spatialPoolerState struct {
+	SerialVersion int
+	Params        SpParams
+	//Representation of the potential pool and connected synapse matrices
+	Matrix string
+
+	// Extra parameter settings
+	SynPermBelowStimulusInc float64
+	SynPermMin              float64
+	SynPermMax              float64
+	SynPermTrimThreshold    float64
+	UpdatePeriod            int
+	InitConnectedPct        float64
+
+	// Internal state
+	Version           float64
+	IterationNum      int
+	IterationLearnNum int
+
+	PotentialPools    [][]int
+	PermanenceIndices [][]int
+	PermanenceValues  [][]float64
+	TieBreaker        []float64
+	ConnectedSynapses [][]int
+	ConnectedCounts   []int
+
+	OverlapDutyCycles    []float64
+	ActiveDutyCycles     []float64
+	MinOverlapDutyCycles []float64
+	MinActiveDutyCycles  []float64
+	BoostFactors         []float64
+
+	InhibitionRadius int
+
+	Rand utils.RandState
+}
+
+//Returns the params describing the current configuration of the pooler
+func (sp *SpatialPooler) params() SpParams {
+	p := SpParams{}
+	p.InputDimensions = sp.InputDimensions
+	p.ColumnDimensions = sp.ColumnDimensions
+	p.PotentialRadius = sp.PotentialRadius
+	p.PotentialPct = sp.PotentialPct
+	p.GlobalInhibition = sp.GlobalInhibition
+	p.LocalAreaDensity = sp.LocalAreaDensity
+	p.NumActiveColumnsPerInhArea = sp.NumActiveColumnsPerInhArea
+	p.StimulusThreshold = sp.StimulusThreshold
+	p.SynPermInactiveDec = sp.SynPermInactiveDec
+	p.SynPermActiveInc = sp.SynPermActiveInc
+	p.SynPermConnected = sp.SynPermConnected
+	p.MinPctOverlapDutyCycle = sp.MinPctOverlapDutyCycles
+	p.MinPctActiveDutyCycle = sp.MinPctActiveDutyCycles
+	p.DutyCyclePeriod = sp.DutyCyclePeriod
+	p.MaxBoost = sp.MaxBoost
+	p.BoostMode = sp.BoostMode
+	p.BoostStrength = sp.BoostStrength
+	p.Seed = sp.Seed
+	p.SpVerbosity = sp.SpVerbosity
+	p.NumWorkers = sp.NumWorkers
+	p.WrapAround = sp.WrapAround
+	return p
+}
+
+//Writes the complete state of the spatial pooler to w. The pooler
+//can be restored with LoadSpatialPooler.
+func (sp *SpatialPooler) Save(w io.Writer) error {
+	state := spatialPoolerState{}
+	state.SerialVersion = spatialPoolerSerialVersion
+	state.Params = sp.params()
+	state.Matrix = matrixRepresentation(sp.potentialPools)
+
+	state.SynPermBelowStimulusInc = sp.SynPermBelowStimulusInc
+	state.SynPermMin = sp.SynPermMin
+	state.SynPermMax = sp.SynPermMax
+	state.SynPermTrimThreshold = sp.SynPermTrimThreshold
+	state.UpdatePeriod = sp.UpdatePeriod
+	state.InitConnectedPct = sp.InitConnectedPct
+
+	state.Version = sp.Version
+	state.IterationNum = sp.IterationNum
+	state.IterationLearnNum = sp.IterationLearnNum
+
+	state.PotentialPools = make([][]int, sp.numColumns)
+	state.PermanenceIndices = make([][]int, sp.numColumns)
+	state.PermanenceValues = make([][]float64, sp.numColumns)
+	state.ConnectedSynapses = make([][]int, sp.numColumns)
+	for i := 0; i < sp.numColumns; i++ {
+		state.PotentialPools[i] = sp.potentialPools.GetRowIndices(i)
+		state.ConnectedSynapses[i] = sp.connectedSynapses.GetRowIndices(i)
+		for j := 0; j < sp.numInputs; j++ {
+			perm := sp.permanences.Get(i, j)
+			if perm != 0 {
+				state.PermanenceIndices[i] = append(state.PermanenceIndices[i], j)
+				state.PermanenceValues[i] = append(state.PermanenceValues[i], perm)
+			}
+		}
+	}
+
+	state.TieBreaker = sp.tieBreaker
+	state.ConnectedCounts = sp.connectedCounts
+	state.OverlapDutyCycles = sp.overlapDutyCycles
+	state.ActiveDutyCycles = sp.activeDutyCycles
+	state.MinOverlapDutyCycles = sp.minOverlapDutyCycles
+	state.MinActiveDutyCycles = sp.minActiveDutyCycles
+	state.BoostFactors = sp.boostFactors
+	state.InhibitionRadius = sp.inhibitionRadius
+	state.Rand = sp.rngSource.State()
+
+	return gob.NewEncoder(w).Encode(&state)
+}
+
+/*
+	Restores a spatial pooler previously written with Save, it uses the
+default inhibitor. The synapse matrices are created with newMatrix, or with
+the representation of the saved pooler when nil. Poolers saved with a
+custom representation require newMatrix.
+*/
+func LoadSpatialPooler(r io.Reader, newMatrix BinaryMatrixFactory) (*SpatialPooler, error) {
+	state := spatialPoolerState{}
+	if err := gob.NewDecoder(r).Decode(&state); err != nil {
+		return nil, err
+	}
+	if state.SerialVersion != spatialPoolerSerialVersion {
+		return nil, fmt.Errorf("unsupported spatial pooler version %v", state.SerialVersion)
+	}
+	newMatrix, err := snapshotMatrixFactory(state.Matrix, newMatrix)
+	if err != nil {
+		return nil, err
+	}
+
+	p := state.Params
+	sp := new(SpatialPooler)
+	sp.numColumns = p.NumColumns()
+	sp.numInputs = p.NumInputs()
+
+	if len(state.PotentialPools) != sp.numColumns ||
+		len(state.PermanenceIndices) != sp.numColumns ||
+		len(state.PermanenceValues) != sp.numColumns ||
+		len(state.ConnectedSynapses) != sp.numColumns ||
+		len(state.TieBreaker) != sp.numColumns ||
+		len(state.ConnectedCounts) != sp.numColumns ||
+		len(state.OverlapDutyCycles) != sp.numColumns ||
+		len(state.ActiveDutyCycles) != sp.numColumns ||
+		len(state.MinOverlapDutyCycles) != sp.numColumns ||
+		len(state.MinActiveDutyCycles) != sp.numColumns ||
+		len(state.BoostFactors) != sp.numColumns {
+		return nil, fmt.Errorf("spatial pooler state does not match %v columns", sp.numColumns)
+	}
+
+	sp.InputDimensions = p.InputDimensions
+	sp.ColumnDimensions = p.ColumnDimensions
+	sp.PotentialRadius = p.PotentialRadius
+	sp.PotentialPct = p.PotentialPct
+	sp.GlobalInhibition = p.GlobalInhibition
+	sp.LocalAreaDensity = p.LocalAreaDensity
+	sp.NumActiveColumnsPerInhArea = p.NumActiveColumnsPerInhArea
+	sp.StimulusThreshold = p.StimulusThreshold
+	sp.SynPermInactiveDec = p.SynPermInactiveDec
+	sp.SynPermActiveInc = p.SynPermActiveInc
+	sp.SynPermConnected = p.SynPermConnected
+	sp.MinPctOverlapDutyCycles = p.MinPctOverlapDutyCycle
+	sp.MinPctActiveDutyCycles = p.MinPctActiveDutyCycle
+	sp.DutyCyclePeriod = p.DutyCyclePeriod
+	sp.MaxBoost = p.MaxBoost
+	sp.BoostMode = p.BoostMode
+	sp.BoostStrength = p.BoostStrength
+	sp.Seed = p.Seed
+	sp.SpVerbosity = p.SpVerbosity
+	sp.NumWorkers = p.NumWorkers
+	sp.WrapAround = p.WrapAround
+	sp.Inhibitor = DefaultInhibitor{}
+
+	sp.SynPermBelowStimulusInc = state.SynPermBelowStimulusInc
+	sp.SynPermMin = state.SynPermMin
+	sp.SynPermMax = state.SynPermMax
+	sp.SynPermTrimThreshold = state.SynPermTrimThreshold
+	sp.UpdatePeriod = state.UpdatePeriod
+	sp.InitConnectedPct = state.InitConnectedPct
+
+	sp.Version = state.Version
+	sp.IterationNum = state.IterationNum
+	sp.IterationLearnNum = state.IterationLearnNum
+
+	sp.potentialPools = newMatrix(sp.numColumns, sp.numInputs)
+	sp.connectedSynapses = newMatrix(sp.numColumns, sp.numInputs)
+	elms := make(map[int]float64, int(float64(sp.numColumns*sp.numInputs)*0.3))
+	sp.permanences = matrix.MakeSparseMatrix(elms, sp.numColumns, sp.numInputs)
+
+	for i := 0; i < sp.numColumns; i++ {
+		sp.potentialPools.ReplaceRowByIndices(i, state.PotentialPools[i])
+		sp.connectedSynapses.ReplaceRowByIndices(i, state.ConnectedSynapses[i])
+		if len(state.PermanenceIndices[i]) != len(state.PermanenceValues[i]) {
+			return nil, fmt.Errorf("corrupt permanences for column %v", i)
+		}
+		for j, idx := range state.PermanenceIndices[i] {
+			sp.permanences.Set(i, idx, state.PermanenceValues[i][j])
+		}
+	}
+
+	sp.tieBreaker = state.TieBreaker
+	sp.connectedCounts = state.ConnectedCounts
+	sp.overlapDutyCycles = state.OverlapDutyCycles
+	sp.activeDutyCycles = state.ActiveDutyCycles
+	sp.minOverlapDutyCycles = state.MinOverlapDutyCycles
+	sp.minActiveDutyCycles = state.MinActiveDutyCycles
+	sp.boostFactors = state.BoostFactors
+	sp.inhibitionRadius = state.InhibitionRadius
+	rngSource, err := utils.NewRandSourceFromState(state.Rand)
+	if err != nil {
+		return nil, err
+	}
+	sp.rngSource = rngSource
+	sp.rng = rand.New(sp.rngSource)
+
+	return sp, nil
+}
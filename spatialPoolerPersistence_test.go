@@ -0,0 +1,121 @@
+package htm
+
+import (
+	"bytes"
+	"github.com/zacg/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+func TestSpatialPoolerSaveLoad(t *testing.T) {
+	spParams := NewSpParams()
+	spParams.InputDimensions = []int{50}
+	spParams.ColumnDimensions = []int{100}
+	spParams.GlobalInhibition = true
+	spParams.NumActiveColumnsPerInhArea = 5
+	sp := NewSpatialPooler(spParams)
+
+	inputs := make([][]bool, 20)
+	for i := range inputs {
+		inputs[i] = make([]bool, sp.numInputs)
+		for j := range inputs[i] {
+			inputs[i][j] = rand.Float64() > 0.8
+		}
+	}
+
+	for _, input := range inputs {
+		sp.Compute(input, true)
+	}
+
+	var buf bytes.Buffer
+	err := sp.Save(&buf)
+	assert.Equal(t, nil, err)
+
+	sp2, err := LoadSpatialPooler(&buf, nil)
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, sp.params(), sp2.params())
+	assert.Equal(t, sp.IterationNum, sp2.IterationNum)
+	assert.Equal(t, sp.inhibitionRadius, sp2.inhibitionRadius)
+	assert.Equal(t, sp.boostFactors, sp2.boostFactors)
+	assert.Equal(t, sp.activeDutyCycles, sp2.activeDutyCycles)
+	assert.Equal(t, sp.connectedCounts, sp2.connectedCounts)
+	assert.Equal(t, sp.potentialPools.Flatten(), sp2.potentialPools.Flatten())
+	assert.Equal(t, sp.connectedSynapses.Flatten(), sp2.connectedSynapses.Flatten())
+	for i := 0; i < sp.numColumns; i++ {
+		assert.Equal(t, GetRowFromSM(sp.permanences, i), GetRowFromSM(sp2.permanences, i))
+	}
+
+	// Both poolers should continue identically
+	for _, input := range inputs {
+		active, _ := sp.Compute(input, true)
+		active2, _ := sp2.Compute(input, true)
+		assert.Equal(t, active, active2)
+	}
+
+}
+
+func TestLoadSpatialPoolerInvalid(t *testing.T) {
+	_, err := LoadSpatialPooler(bytes.NewBufferString("garbage"), nil)
+	assert.True(t, err != nil)
+}
+
+//Custom matrix representation used to test persistence of representations
+//unknown to the snapshot
+type customBinaryMatrix struct {
+	*DenseBinaryMatrix
+}
+
+func TestSpatialPoolerSaveLoadMatrixRepresentation(t *testing.T) {
+	factories := map[string]BinaryMatrixFactory{
+		"sparse": SparseMatrixFactory,
+		"dense":  DenseMatrixFactory,
+		"bitset": BitsetMatrixFactory,
+		"csr":    CSRMatrixFactory,
+		"csc":    CSCMatrixFactory,
+	}
+
+	input := make([]bool, 50)
+	for i := 0; i < 10; i++ {
+		input[i*5] = true
+	}
+
+	for name, factory := range factories {
+		spParams := NewSpParams()
+		spParams.InputDimensions = []int{50}
+		spParams.ColumnDimensions = []int{20}
+		spParams.GlobalInhibition = true
+		spParams.NumActiveColumnsPerInhArea = 3
+		spParams.MatrixFactory = factory
+		sp := NewSpatialPooler(spParams)
+		sp.Compute(input, true)
+
+		var buf bytes.Buffer
+		assert.Equal(t, nil, sp.Save(&buf))
+		sp2, err := LoadSpatialPooler(&buf, nil)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, name, matrixRepresentation(sp2.potentialPools))
+		assert.Equal(t, name, matrixRepresentation(sp2.connectedSynapses))
+		assert.Equal(t, sp.connectedSynapses.Flatten(), sp2.connectedSynapses.Flatten())
+	}
+
+	// custom representations need their factory
+	spParams := NewSpParams()
+	spParams.InputDimensions = []int{50}
+	spParams.ColumnDimensions = []int{20}
+	custom := func(height int, width int) BinaryMatrix {
+		return customBinaryMatrix{NewDenseBinaryMatrix(height, width)}
+	}
+	spParams.MatrixFactory = custom
+	sp := NewSpatialPooler(spParams)
+
+	var buf bytes.Buffer
+	assert.Equal(t, nil, sp.Save(&buf))
+	saved := buf.Bytes()
+	_, err := LoadSpatialPooler(bytes.NewBuffer(saved), nil)
+	assert.True(t, err != nil)
+	sp2, err := LoadSpatialPooler(bytes.NewBuffer(saved), custom)
+	assert.Equal(t, nil, err)
+	_, ok := sp2.potentialPools.(customBinaryMatrix)
+	assert.True(t, ok)
+}
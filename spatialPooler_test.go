@@ -37,6 +37,7 @@ func TestPermanenceInit(t *testing.T) {
 	sp.SynPermActiveInc = 0.1
 
 	sp.PotentialRadius = 2
+	sp.rng = rand.New(utils.NewRandSource(42))
 	connectedPct := 1.0
 	mask := []bool{true, true, true, false, false, false, false, false, true, true}
 	perm := sp.initPermanence(mask, connectedPct)
@@ -463,19 +464,13 @@ func TestCalculateOverlap(t *testing.T) {
 func TestInhibitColumns(t *testing.T) {
 	sp := SpatialPooler{}
 
-	globalValue := []int{1}
-	localValue := []int{2}
-	lastGlobalDensity := 0.0
-	lastLocalDensity := 0.0
-	globalFunc := func(overlaps []float64, density float64) []int {
-		lastGlobalDensity = density
-		return globalValue
-	}
-
-	localFunc := func(overlaps []float64, density float64) []int {
-		lastLocalDensity = density
-		return localValue
-	}
+	lastGlobal := false
+	lastDensity := 0.0
+	sp.Inhibitor = InhibitorFunc(func(sp *SpatialPooler, overlaps []float64, density float64) []int {
+		lastGlobal = sp.inhibitsGlobally()
+		lastDensity = density
+		return []int{1}
+	})
 
 	sp.ColumnDimensions = []int{5}
 	sp.numColumns = 5
@@ -492,8 +487,9 @@ func TestInhibitColumns(t *testing.T) {
 	sp.GlobalInhibition = true
 	sp.inhibitionRadius = 5
 	trueDensity := sp.LocalAreaDensity
-	sp.InhibitColumns(overlaps, globalFunc, localFunc)
-	assert.Equal(t, trueDensity, lastGlobalDensity)
+	assert.Equal(t, []int{1}, sp.InhibitColumns(overlaps))
+	assert.True(t, lastGlobal)
+	assert.Equal(t, trueDensity, lastDensity)
 
 	//----- 2
 	sp.ColumnDimensions = []int{50, 10}
@@ -506,8 +502,9 @@ func TestInhibitColumns(t *testing.T) {
 	// 0.1 * (2*9+1)**2 = 22.5
 	trueDensity = sp.LocalAreaDensity
 	overlaps = utils.RandomSample(sp.numColumns)
-	sp.InhibitColumns(overlaps, globalFunc, localFunc)
-	assert.Equal(t, trueDensity, lastLocalDensity)
+	sp.InhibitColumns(overlaps)
+	assert.False(t, lastGlobal)
+	assert.Equal(t, trueDensity, lastDensity)
 
 	// Test translation of numColumnsPerInhArea into local area density
 	sp.ColumnDimensions = []int{10, 10}
@@ -521,8 +518,9 @@ func TestInhibitColumns(t *testing.T) {
 	overlaps = utils.RandomSample(sp.numColumns)
 
 	// 3.0 / (((2*4) + 1) ** 2)
-	sp.InhibitColumns(overlaps, globalFunc, localFunc)
-	assert.Equal(t, trueDensity, lastLocalDensity)
+	sp.InhibitColumns(overlaps)
+	assert.False(t, lastGlobal)
+	assert.Equal(t, trueDensity, lastDensity)
 
 	// Test clipping of local area density to 0.5
 	sp.ColumnDimensions = []int{10, 10}
@@ -535,8 +533,9 @@ func TestInhibitColumns(t *testing.T) {
 	trueDensity = 0.5
 	overlaps = utils.RandomSample(sp.numColumns)
 
-	sp.InhibitColumns(overlaps, globalFunc, localFunc)
-	assert.Equal(t, trueDensity, lastLocalDensity)
+	sp.InhibitColumns(overlaps)
+	assert.False(t, lastGlobal)
+	assert.Equal(t, trueDensity, lastDensity)
 
 }
 
@@ -1328,6 +1327,7 @@ func TestMapPotential1Column1Input(t *testing.T) {
 	sp.numColumns = 1
 	sp.PotentialRadius = 2
 	sp.PotentialPct = 1
+	sp.rng = rand.New(utils.NewRandSource(42))
 
 	expectedMask := []bool{true}
 	mask := sp.mapPotential(0, false)
@@ -1342,6 +1342,7 @@ func TestMapPotential1D(t *testing.T) {
 	sp.numColumns = 4
 	sp.PotentialRadius = 2
 	sp.PotentialPct = 1
+	sp.rng = rand.New(utils.NewRandSource(42))
 
 	expectedMask := []bool{true, true, true, false, false, false, false, false, false, false}
 	mask := sp.mapPotential(0, false)
@@ -1405,15 +1406,14 @@ func TestCompute1(t *testing.T) {
 		}
 	}
 
-	inhibitColumnsMock := func(overlaps []float64, inhibitColumnsGlobal, inhibitColumnsLocal inhibitColumnsFunc) []int {
+	sp.Inhibitor = InhibitorFunc(func(sp *SpatialPooler, overlaps []float64, density float64) []int {
 		return []int{0, 1, 2, 3, 4}
-	}
+	})
 
 	inputVector := utils.Make1DBool([]int{1, 0, 1, 0, 1, 0, 0, 1, 1})
-	activeArray := make([]bool, 5)
 
 	for i := 0; i < 20; i++ {
-		sp.Compute(inputVector, true, activeArray, inhibitColumnsMock)
+		sp.Compute(inputVector, true)
 	}
 
 	for i := 0; i < 20; i++ {
@@ -1447,15 +1447,14 @@ func TestCompute2(t *testing.T) {
 	spParams.MaxBoost = 10.0
 	sp := NewSpatialPooler(spParams)
 
-	inhibitColumnsMock := func(overlaps []float64, inhibitColumnsGlobal, inhibitColumnsLocal inhibitColumnsFunc) []int {
+	sp.Inhibitor = InhibitorFunc(func(sp *SpatialPooler, overlaps []float64, density float64) []int {
 		return []int{0, 1, 2, 3, 4}
-	}
+	})
 
 	inputVector := utils.Make1DBool([]int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1})
-	activeArray := make([]bool, 5)
 
 	for i := 0; i < 20; i++ {
-		sp.Compute(inputVector, true, activeArray, inhibitColumnsMock)
+		sp.Compute(inputVector, true)
 	}
 
 	for i := 0; i < sp.numColumns; i++ {
@@ -1571,3 +1570,45 @@ func AddDenseToSparseHelper(dense [][]float64, m *matrix.SparseMatrix) {
 		}
 	}
 }
+
+func TestSpParamsValidate(t *testing.T) {
+	spParams := NewSpParams()
+	spParams.InputDimensions = []int{10}
+	spParams.ColumnDimensions = []int{5}
+	assert.Nil(t, spParams.Validate())
+
+	invalid := spParams
+	invalid.ColumnDimensions = []int{0}
+	err, ok := invalid.Validate().(*ParamError)
+	assert.True(t, ok)
+	assert.Equal(t, "ColumnDimensions", err.Field)
+
+	invalid = spParams
+	invalid.InputDimensions = []int{}
+	err, ok = invalid.Validate().(*ParamError)
+	assert.True(t, ok)
+	assert.Equal(t, "InputDimensions", err.Field)
+
+	invalid = spParams
+	invalid.NumActiveColumnsPerInhArea = 0
+	invalid.LocalAreaDensity = 0.8
+	err, ok = invalid.Validate().(*ParamError)
+	assert.True(t, ok)
+	assert.Equal(t, "NumActiveColumnsPerInhArea", err.Field)
+
+	invalid.LocalAreaDensity = 0.1
+	assert.Nil(t, invalid.Validate())
+
+	invalid = spParams
+	invalid.SynPermActiveInc = 0.3
+	invalid.SynPermConnected = 0.1
+	err, ok = invalid.Validate().(*ParamError)
+	assert.True(t, ok)
+	assert.Equal(t, "SynPermActiveInc", err.Field)
+
+	sp := NewSpatialPooler(spParams)
+	assert.Nil(t, sp.ValidateInput(make([]bool, 10)))
+	err, ok = sp.ValidateInput(make([]bool, 9)).(*ParamError)
+	assert.True(t, ok)
+	assert.Equal(t, "inputVector", err.Field)
+}
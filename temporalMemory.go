@@ -59,6 +59,20 @@ func NewTemporalMemoryParams() *TemporalMemoryParams {
 	return p
 }
 
+//Validates params, returns a *ParamError naming the first invalid field
+func (p *TemporalMemoryParams) Validate() error {
+	if err := validateConnectionDimensions(p.CellsPerColumn, p.ColumnDimensions); err != nil {
+		return err
+	}
+	if p.InitialPermanence < 0 || p.InitialPermanence > 1 {
+		return &ParamError{Field: "InitialPermanence", Reason: "must be between 0 and 1"}
+	}
+	if p.ConnectedPermanence < 0 || p.ConnectedPermanence > 1 {
+		return &ParamError{Field: "ConnectedPermanence", Reason: "must be between 0 and 1"}
+	}
+	return nil
+}
+
 /*
 Temporal memory
 */
@@ -70,22 +84,35 @@ type TemporalMemory struct {
 	ActiveSynapsesForSegment map[int][]int
 	WinnerCells              []int
 	Connections              *TemporalMemoryConnections
+	rng                      *rand.Rand
+	rngSource                *utils.RandSource
+
+	//anomaly bookkeeping of the last compute
+	activeColumns        []int
+	prevPredictedColumns []int
+	anomalyScore         float64
 }
 
 //Create new temporal memory
 func NewTemporalMemory(params *TemporalMemoryParams) *TemporalMemory {
+	if err := params.Validate(); err != nil {
+		panic(err)
+	}
 	tm := new(TemporalMemory)
 	tm.params = params
 	tm.Connections = NewTemporalMemoryConnections(params.MaxNewSynapseCount,
 		params.CellsPerColumn, params.ColumnDimensions)
-	//TODO: refactor into encapsulated RNG
-	rand.Seed(int64(params.Seed))
+	tm.rngSource = utils.NewRandSource(int64(params.Seed))
+	tm.rng = rand.New(tm.rngSource)
 	return tm
 }
 
 //Feeds input record through TM, performing inference and learning.
 //Updates member variables with new state.
 func (tm *TemporalMemory) Compute(activeColumns []int, learn bool) {
+	tm.activeColumns = uniqueSortedColumns(activeColumns)
+	tm.prevPredictedColumns = tm.PredictedColumns()
+	tm.anomalyScore = ComputeRawAnomalyScore(tm.activeColumns, tm.prevPredictedColumns)
 
 	activeCells, winnerCells, activeSynapsesForSegment, activeSegments, predictiveCells := tm.computeFn(activeColumns,
 		tm.PredictiveCells,
@@ -103,6 +130,50 @@ func (tm *TemporalMemory) Compute(activeColumns []int, learn bool) {
 
 }
 
+//Feeds the active columns of an SDR through TM, returns the active cells
+//with the column dimensions and cells per column as dimensions
+func (tm *TemporalMemory) ComputeSDR(activeColumns *SDR, learn bool) *SDR {
+	tm.Compute(activeColumns.Sparse(), learn)
+	return tm.cellsSDR(tm.ActiveCells)
+}
+
+//Returns the predictive cells as an SDR, see ComputeSDR
+func (tm *TemporalMemory) PredictiveCellsSDR() *SDR {
+	return tm.cellsSDR(tm.PredictiveCells)
+}
+
+func (tm *TemporalMemory) cellsSDR(cells []int) *SDR {
+	dims := make([]int, len(tm.params.ColumnDimensions), len(tm.params.ColumnDimensions)+1)
+	copy(dims, tm.params.ColumnDimensions)
+	dims = append(dims, tm.params.CellsPerColumn)
+	return NewSDRFromSparse(cells, dims...)
+}
+
+//Returns the columns of the predictive cells
+func (tm *TemporalMemory) PredictedColumns() []int {
+	columns := make([]int, len(tm.PredictiveCells))
+	for idx, cell := range tm.PredictiveCells {
+		columns[idx] = tm.Connections.ColumnForCell(cell)
+	}
+	return uniqueSortedColumns(columns)
+}
+
+//Returns the fraction of active columns of the last compute that were
+//not predicted by the previous compute
+func (tm *TemporalMemory) AnomalyScore() float64 {
+	return tm.anomalyScore
+}
+
+//Returns the active columns of the last compute that were predicted
+func (tm *TemporalMemory) PredictedActiveColumns() []int {
+	return filterPredictedColumns(tm.activeColumns, tm.prevPredictedColumns, true)
+}
+
+//Returns the active columns of the last compute that were not predicted
+func (tm *TemporalMemory) UnpredictedActiveColumns() []int {
+	return filterPredictedColumns(tm.activeColumns, tm.prevPredictedColumns, false)
+}
+
 // helper for compute().
 //Returns new state
 func (tm *TemporalMemory) computeFn(activeColumns []int,
@@ -412,7 +483,7 @@ func (tm *TemporalMemory) getLeastUsedCell(column int, connections *TemporalMemo
 	}
 
 	//pick random cell
-	return leastUsedCells[rand.Intn(len(leastUsedCells))]
+	return leastUsedCells[tm.rng.Intn(len(leastUsedCells))]
 }
 
 //Returns the synapses on a segment that are active due to lateral input
@@ -477,7 +548,7 @@ func (tm *TemporalMemory) pickCellsToLearnOn(n int, segment int,
 
 	//Shuffle candidates
 	for i := range candidates {
-		j := rand.Intn(i + 1)
+		j := tm.rng.Intn(i + 1)
 		candidates[i], candidates[j] = candidates[j], candidates[i]
 	}
 
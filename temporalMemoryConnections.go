@@ -40,14 +40,20 @@ type TemporalMemoryConnections struct {
 	maxSynapseCount int
 }
 
-//Create a new temporal memory
-func NewTemporalMemoryConnections(maxSynCount int, cellsPerColumn int, colDimensions []int) *TemporalMemoryConnections {
+func validateConnectionDimensions(cellsPerColumn int, colDimensions []int) error {
 	if len(colDimensions) < 1 {
-		panic("Column dimensions must be greater than 0")
+		return &ParamError{Field: "ColumnDimensions", Reason: "must have at least 1 dimension"}
 	}
-
 	if cellsPerColumn < 1 {
-		panic("Number of cells per column must be greater than 0")
+		return &ParamError{Field: "CellsPerColumn", Reason: "must be greater than 0"}
+	}
+	return nil
+}
+
+//Create a new temporal memory
+func NewTemporalMemoryConnections(maxSynCount int, cellsPerColumn int, colDimensions []int) *TemporalMemoryConnections {
+	if err := validateConnectionDimensions(cellsPerColumn, colDimensions); err != nil {
+		panic(err)
 	}
 
 	c := new(TemporalMemoryConnections)
@@ -0,0 +1,162 @@
+package htm
+
+import (
+	"encoding/gob"
+	"fmt"
+	"github.com/nupic-community/htm/utils"
+	"io"
+	"math/rand"
+)
+
+//Version of the temporal memory serialization format, bump when
+//temporalMemoryState or temporalMemoryConnectionsState change in an
+//incompatible way
+const temporalMemorySerialVersion = 3
+
+/*
+Serializable snapshot of temporal memory connections. Only segments and
+synapses are stored, the lookup indexes are rebuilt on load by replaying
+segment and synapse creation in their original order.
+*/
+type temporalMemoryConnectionsState struct {
+	SerialVersion    int
+	ColumnDimensions []int
+	CellsPerColumn   int
+	MaxSynapseCount  int
+	// cell index for each segment
+	Segments []int
+	Synapses []TmSynapse
+}
+
+//Serializable snapshot of a temporal memory
+type temporalMemoryState struct {
+	SerialVersion            int
+	Params                   TemporalMemoryParams
+	ActiveCells              []int
+	PredictiveCells          []int
+	ActiveSegments           []int
+	ActiveSynapsesForSegment map[int][]int
+	WinnerCells              []int
+	ActiveColumns            []int
+	PrevPredictedColumns     []int
+	AnomalyScore             float64
+	Connections              temporalMemoryConnectionsState
+	Rand                     utils.RandState
+}
+
+func (tmc *TemporalMemoryConnections) state() temporalMemoryConnectionsState {
+	state := temporalMemoryConnectionsState{}
+	state.SerialVersion = temporalMemorySerialVersion
+	state.ColumnDimensions = tmc.ColumnDimensions
+	state.CellsPerColumn = tmc.CellsPerColumn
+	state.MaxSynapseCount = tmc.maxSynapseCount
+	state.Segments = tmc.segments
+	state.Synapses = make([]TmSynapse, len(tmc.synapses))
+	for idx, syn := range tmc.synapses {
+		state.Synapses[idx] = *syn
+	}
+	return state
+}
+
+func (state *temporalMemoryConnectionsState) connections() (*TemporalMemoryConnections, error) {
+	if state.SerialVersion != temporalMemorySerialVersion {
+		return nil, fmt.Errorf("unsupported temporal memory version %v", state.SerialVersion)
+	}
+	if len(state.ColumnDimensions) < 1 || state.CellsPerColumn < 1 {
+		return nil, fmt.Errorf("invalid temporal memory connections dimensions")
+	}
+
+	tmc := NewTemporalMemoryConnections(state.MaxSynapseCount, state.CellsPerColumn, state.ColumnDimensions)
+	numCells := tmc.NumberOfcells()
+
+	for _, cell := range state.Segments {
+		if cell < 0 || cell >= numCells {
+			return nil, fmt.Errorf("segment references invalid cell %v", cell)
+		}
+		tmc.CreateSegment(cell)
+	}
+
+	for _, syn := range state.Synapses {
+		if syn.Segment < 0 || syn.Segment >= len(tmc.segments) {
+			return nil, fmt.Errorf("synapse references invalid segment %v", syn.Segment)
+		}
+		if syn.SourceCell < 0 || syn.SourceCell >= numCells {
+			return nil, fmt.Errorf("synapse references invalid cell %v", syn.SourceCell)
+		}
+		tmc.CreateSynapse(syn.Segment, syn.SourceCell, syn.Permanence)
+	}
+
+	return tmc, nil
+}
+
+//Writes connections to w in a compact binary format. The connections
+//can be restored with LoadTemporalMemoryConnections.
+func (tmc *TemporalMemoryConnections) Save(w io.Writer) error {
+	state := tmc.state()
+	return gob.NewEncoder(w).Encode(&state)
+}
+
+//Restores connections previously written with Save
+func LoadTemporalMemoryConnections(r io.Reader) (*TemporalMemoryConnections, error) {
+	state := temporalMemoryConnectionsState{}
+	if err := gob.NewDecoder(r).Decode(&state); err != nil {
+		return nil, err
+	}
+	return state.connections()
+}
+
+//Writes params, connections and sequence state of the temporal memory
+//to w. The temporal memory can be restored with LoadTemporalMemory.
+func (tm *TemporalMemory) Save(w io.Writer) error {
+	state := temporalMemoryState{}
+	state.SerialVersion = temporalMemorySerialVersion
+	state.Params = *tm.params
+	state.ActiveCells = tm.ActiveCells
+	state.PredictiveCells = tm.PredictiveCells
+	state.ActiveSegments = tm.ActiveSegments
+	state.ActiveSynapsesForSegment = tm.ActiveSynapsesForSegment
+	state.WinnerCells = tm.WinnerCells
+	state.ActiveColumns = tm.activeColumns
+	state.PrevPredictedColumns = tm.prevPredictedColumns
+	state.AnomalyScore = tm.anomalyScore
+	state.Connections = tm.Connections.state()
+	state.Rand = tm.rngSource.State()
+	return gob.NewEncoder(w).Encode(&state)
+}
+
+//Restores a temporal memory previously written with Save
+func LoadTemporalMemory(r io.Reader) (*TemporalMemory, error) {
+	state := temporalMemoryState{}
+	if err := gob.NewDecoder(r).Decode(&state); err != nil {
+		return nil, err
+	}
+	if state.SerialVersion != temporalMemorySerialVersion {
+		return nil, fmt.Errorf("unsupported temporal memory version %v", state.SerialVersion)
+	}
+
+	connections, err := state.Connections.connections()
+	if err != nil {
+		return nil, err
+	}
+
+	tm := new(TemporalMemory)
+	params := state.Params
+	tm.params = &params
+	tm.ActiveCells = state.ActiveCells
+	tm.PredictiveCells = state.PredictiveCells
+	tm.ActiveSegments = state.ActiveSegments
+	tm.ActiveSynapsesForSegment = state.ActiveSynapsesForSegment
+	tm.WinnerCells = state.WinnerCells
+	tm.activeColumns = state.ActiveColumns
+	tm.prevPredictedColumns = state.PrevPredictedColumns
+	tm.anomalyScore = state.AnomalyScore
+	tm.Connections = connections
+	rngSource, err := utils.NewRandSourceFromState(state.Rand)
+	if err != nil {
+		return nil, err
+	}
+	tm.rngSource = rngSource
+	tm.rng = rand.New(tm.rngSource)
+
+	return tm, nil
+}
@@ -0,0 +1,79 @@
+package htm
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTemporalMemoryConnectionsSaveLoad(t *testing.T) {
+	c := NewTemporalMemoryConnections(1000, 4, []int{64, 64})
+	c.CreateSegment(10)
+	c.CreateSegment(3)
+	c.CreateSegment(10)
+	c.CreateSynapse(0, 483, 0.1284)
+	c.CreateSynapse(2, 12, 0.5)
+	c.CreateSynapse(0, 12, 0.75)
+
+	var buf bytes.Buffer
+	assert.Nil(t, c.Save(&buf))
+
+	c2, err := LoadTemporalMemoryConnections(&buf)
+	assert.Nil(t, err)
+
+	assert.Equal(t, c.ColumnDimensions, c2.ColumnDimensions)
+	assert.Equal(t, c.CellsPerColumn, c2.CellsPerColumn)
+	assert.Equal(t, []int{0, 2}, c2.SegmentsForCell(10))
+	assert.Equal(t, []int{1}, c2.SegmentsForCell(3))
+	assert.Equal(t, 3, c2.CellForSegment(1))
+	assert.Equal(t, []int{0, 2}, c2.SynapsesForSegment(0))
+	assert.Equal(t, []int{1, 2}, c2.SynapsesForSourceCell(12))
+	assert.Equal(t, 0.75, c2.DataForSynapse(2).Permanence)
+	assert.Equal(t, 483, c2.DataForSynapse(0).SourceCell)
+}
+
+func TestTemporalMemorySaveLoad(t *testing.T) {
+	tmp := NewTemporalMemoryParams()
+	tmp.ColumnDimensions = []int{32}
+	tmp.CellsPerColumn = 4
+	tmp.ActivationThreshold = 3
+	tmp.MinThreshold = 2
+	tmp.InitialPermanence = 0.5
+	tm := NewTemporalMemory(tmp)
+
+	sequence := [][]int{{0, 1, 2, 3}, {4, 5, 6, 7}, {8, 9, 10, 11}, {12, 13, 14, 15}}
+	for i := 0; i < 10; i++ {
+		for _, cols := range sequence {
+			tm.Compute(cols, true)
+		}
+		tm.Reset()
+	}
+	tm.Compute(sequence[0], true)
+
+	var buf bytes.Buffer
+	assert.Nil(t, tm.Save(&buf))
+
+	tm2, err := LoadTemporalMemory(&buf)
+	assert.Nil(t, err)
+
+	assert.Equal(t, *tm.params, *tm2.params)
+	assert.Equal(t, tm.ActiveCells, tm2.ActiveCells)
+	assert.Equal(t, tm.WinnerCells, tm2.WinnerCells)
+	assert.Equal(t, tm.PredictiveCells, tm2.PredictiveCells)
+	assert.Equal(t, tm.ActiveSegments, tm2.ActiveSegments)
+	assert.Equal(t, tm.Connections.state(), tm2.Connections.state())
+
+	// Both should continue learning from the same state
+	for _, cols := range sequence[1:] {
+		tm.Compute(cols, true)
+		tm2.Compute(cols, true)
+		assert.Equal(t, tm.PredictiveCells, tm2.PredictiveCells)
+		assert.Equal(t, tm.Connections.state(), tm2.Connections.state())
+	}
+
+}
+
+func TestLoadTemporalMemoryInvalid(t *testing.T) {
+	_, err := LoadTemporalMemory(bytes.NewBufferString("garbage"))
+	assert.NotNil(t, err)
+}
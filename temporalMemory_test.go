@@ -483,3 +483,25 @@ func TestActivateCorrectlyPredictiveCells(t *testing.T) {
 	assert.Equal(t, []int{32, 823}, predictedColumns)
 
 }
+
+func TestTemporalMemoryParamsValidate(t *testing.T) {
+	tmp := NewTemporalMemoryParams()
+	assert.Nil(t, tmp.Validate())
+
+	tmp.CellsPerColumn = 0
+	err, ok := tmp.Validate().(*ParamError)
+	assert.True(t, ok)
+	assert.Equal(t, "CellsPerColumn", err.Field)
+
+	tmp = NewTemporalMemoryParams()
+	tmp.ColumnDimensions = []int{}
+	err, ok = tmp.Validate().(*ParamError)
+	assert.True(t, ok)
+	assert.Equal(t, "ColumnDimensions", err.Field)
+
+	tmp = NewTemporalMemoryParams()
+	tmp.InitialPermanence = 1.5
+	err, ok = tmp.Validate().(*ParamError)
+	assert.True(t, ok)
+	assert.Equal(t, "InitialPermanence", err.Field)
+}
@@ -43,8 +43,8 @@ type TemporalPoolerParams struct {
 	SegUpdateValidDuration int
 	BurnIn                 int
 	CollectStats           bool
-	//Seed                   int
-	Verbosity int
+	Seed                   int
+	Verbosity              int
 	//checkSynapseConsistency=False, # for cpp only -- ignored
 	TrivialPredictionMethods []PredictorMethod
 	PamLength                int
@@ -54,26 +54,30 @@ type TemporalPoolerParams struct {
 	MaxSeqLength             int
 	MaxSegmentsPerCell       int
 	MaxSynapsesPerSegment    int
-	outputType               TpOutputType
+	AnomalyMode              AnomalyMode
+	//Creates the dynamic state matrices, defaults to sparse matrices
+	//when nil
+	MatrixFactory BinaryMatrixFactory
+	outputType    TpOutputType
 }
 
 type DynamicState struct {
 	//orginally dynamic vars
-	LrnActiveState     *SparseBinaryMatrix // t
-	LrnActiveStateLast *SparseBinaryMatrix // t-1
+	LrnActiveState     BinaryMatrix // t
+	LrnActiveStateLast BinaryMatrix // t-1
 
-	LrnPredictedState     *SparseBinaryMatrix
-	LrnPredictedStateLast *SparseBinaryMatrix
+	LrnPredictedState     BinaryMatrix
+	LrnPredictedStateLast BinaryMatrix
 
-	InfActiveState          *SparseBinaryMatrix
-	InfActiveStateLast      *SparseBinaryMatrix
-	InfActiveStateBackup    *SparseBinaryMatrix
-	InfActiveStateCandidate *SparseBinaryMatrix
+	InfActiveState          BinaryMatrix
+	InfActiveStateLast      BinaryMatrix
+	InfActiveStateBackup    BinaryMatrix
+	InfActiveStateCandidate BinaryMatrix
 
-	InfPredictedState          *SparseBinaryMatrix
-	InfPredictedStateLast      *SparseBinaryMatrix
-	InfPredictedStateBackup    *SparseBinaryMatrix
-	InfPredictedStateCandidate *SparseBinaryMatrix
+	InfPredictedState          BinaryMatrix
+	InfPredictedStateLast      BinaryMatrix
+	InfPredictedStateBackup    BinaryMatrix
+	InfPredictedStateCandidate BinaryMatrix
 
 	CellConfidence          *matrix.DenseMatrix
 	CellConfidenceLast      *matrix.DenseMatrix
@@ -128,7 +132,7 @@ type TemporalPooler struct {
 	lrnIterationIdx int
 	iterationIdx    int
 	segId           int
-	CurrentOutput   *SparseBinaryMatrix
+	CurrentOutput   BinaryMatrix
 	pamCounter      int
 	avgInputDensity float64
 	// Keeps track of the moving average of all learned sequence length.
@@ -139,6 +143,13 @@ type TemporalPooler struct {
 	trivialPredictor     *TrivialPredictor
 	collectSequenceStats bool
 	internalStats        *TpStats
+	rng                  *rand.Rand
+	rngSource            *utils.RandSource
+
+	//anomaly bookkeeping of the last compute
+	lastActiveColumns    []int
+	prevPredictedColumns []int
+	anomalyScore         float64
 
 	//ephemeral state
 
@@ -182,6 +193,7 @@ func NewTemporalPoolerParams() *TemporalPoolerParams {
 	tps.SegUpdateValidDuration = 5
 	tps.BurnIn = 2
 	tps.CollectStats = false
+	tps.Seed = 42
 	tps.Verbosity = 3
 	//tps.TrivialPredictionMethods =
 	tps.PamLength = 1
@@ -196,36 +208,53 @@ func NewTemporalPoolerParams() *TemporalPoolerParams {
 	return tps
 }
 
-//Initializes a new temporal pooler
-func NewTemporalPooler(tParams TemporalPoolerParams) *TemporalPooler {
-	tp := new(TemporalPooler)
-	tp.params = tParams
-
-	//validate args
-	if tParams.PamLength <= 0 {
-		panic("Pam length must be > 0")
+//Validates params, returns a *ParamError naming the first invalid field
+func (tps *TemporalPoolerParams) Validate() error {
+	if tps.PamLength <= 0 {
+		return &ParamError{Field: "PamLength", Reason: "must be greater than 0"}
 	}
 
 	//Fixed size CLA mode
-	if tParams.MaxSegmentsPerCell != -1 || tParams.MaxSynapsesPerSegment != -1 {
-		//validate args
-		if tParams.MaxSegmentsPerCell <= 0 {
-			panic("Maxsegs must be greater than 0")
+	if tps.MaxSegmentsPerCell != -1 || tps.MaxSynapsesPerSegment != -1 {
+		if tps.MaxSegmentsPerCell <= 0 {
+			return &ParamError{Field: "MaxSegmentsPerCell", Reason: "must be greater than 0"}
 		}
-		if tParams.MaxSynapsesPerSegment <= 0 {
-			panic("Max syns per segment must be greater than 0")
+		if tps.MaxSynapsesPerSegment <= 0 {
+			return &ParamError{Field: "MaxSynapsesPerSegment", Reason: "must be greater than 0"}
 		}
-		if tParams.GlobalDecay != 0.0 {
-			panic("Global decay must be 0")
+		if tps.GlobalDecay != 0.0 {
+			return &ParamError{Field: "GlobalDecay", Reason: "must be 0 in fixed size mode"}
 		}
-		if tParams.MaxAge != 0 {
-			panic("Max age must be 0")
+		if tps.MaxAge != 0 {
+			return &ParamError{Field: "MaxAge", Reason: "must be 0 in fixed size mode"}
 		}
-		if !(tParams.MaxSynapsesPerSegment >= tParams.NewSynapseCount) {
-			panic("maxSynapsesPerSegment must be >= newSynapseCount")
+		if tps.MaxSynapsesPerSegment < tps.NewSynapseCount {
+			return &ParamError{Field: "MaxSynapsesPerSegment", Reason: "must be >= NewSynapseCount"}
 		}
 	}
 
+	return nil
+}
+
+//Creates an empty matrix using the configured representation
+func (tp *TemporalPooler) newMatrix(height int, width int) BinaryMatrix {
+	if tp.params.MatrixFactory == nil {
+		return SparseMatrixFactory(height, width)
+	}
+	return tp.params.MatrixFactory(height, width)
+}
+
+//Initializes a new temporal pooler
+func NewTemporalPooler(tParams TemporalPoolerParams) *TemporalPooler {
+	tp := new(TemporalPooler)
+	tp.params = tParams
+	tp.rngSource = utils.NewRandSource(int64(tParams.Seed))
+	tp.rng = rand.New(tp.rngSource)
+
+	if err := tParams.Validate(); err != nil {
+		panic(err)
+	}
+
 	tp.numberOfCells = tParams.NumberOfCols * tParams.CellsPerColumn
 
 	// No point having larger expiration if we are not doing pooling
@@ -261,7 +290,7 @@ func NewTemporalPooler(tParams TemporalPoolerParams) *TemporalPooler {
 	// Trivial prediction algorithms
 
 	if len(tParams.TrivialPredictionMethods) > 0 {
-		tp.trivialPredictor = MakeTrivialPredictor(tParams.NumberOfCols, tParams.TrivialPredictionMethods)
+		tp.trivialPredictor = MakeTrivialPredictor(tParams.NumberOfCols, tParams.TrivialPredictionMethods, tParams.Seed)
 	} else {
 		tp.trivialPredictor = nil
 	}
@@ -274,10 +303,10 @@ func NewTemporalPooler(tParams TemporalPoolerParams) *TemporalPooler {
 	tp.resetCalled = false
 
 	tp.DynamicState = new(DynamicState)
-	tp.DynamicState.InfActiveState = NewSparseBinaryMatrix(tParams.NumberOfCols, tParams.CellsPerColumn)
-	tp.DynamicState.InfPredictedState = NewSparseBinaryMatrix(tParams.NumberOfCols, tParams.CellsPerColumn)
-	tp.DynamicState.LrnActiveState = NewSparseBinaryMatrix(tParams.NumberOfCols, tParams.CellsPerColumn)
-	tp.DynamicState.LrnPredictedState = NewSparseBinaryMatrix(tParams.NumberOfCols, tParams.CellsPerColumn)
+	tp.DynamicState.InfActiveState = tp.newMatrix(tParams.NumberOfCols, tParams.CellsPerColumn)
+	tp.DynamicState.InfPredictedState = tp.newMatrix(tParams.NumberOfCols, tParams.CellsPerColumn)
+	tp.DynamicState.LrnActiveState = tp.newMatrix(tParams.NumberOfCols, tParams.CellsPerColumn)
+	tp.DynamicState.LrnPredictedState = tp.newMatrix(tParams.NumberOfCols, tParams.CellsPerColumn)
 	tp.DynamicState.CellConfidence = matrix.Zeros(tParams.NumberOfCols, tParams.CellsPerColumn)
 	tp.DynamicState.ColConfidence = make([]float64, tParams.NumberOfCols)
 
@@ -394,7 +423,7 @@ func (tp *TemporalPooler) Predict(nSteps int) *matrix.DenseMatrix {
 It can tally up only connected synapses (permanence >= connectedPerm), or
 all the synapses of the segment, at either t or t-1.
 */
-func (tp *TemporalPooler) getSegmentActivityLevel(seg Segment, activeState *SparseBinaryMatrix,
+func (tp *TemporalPooler) getSegmentActivityLevel(seg Segment, activeState BinaryMatrix,
 	connectedSynapsesOnly bool) int {
 	activity := 0
 	//fmt.Println("syn count", len(seg.syns))
@@ -422,7 +451,7 @@ func (tp *TemporalPooler) getSegmentActivityLevel(seg Segment, activeState *Spar
 	 A segment is active if it has >= activationThreshold connected
 	synapses that are active due to activeState.
 */
-func (tp *TemporalPooler) isSegmentActive(seg Segment, activeState *SparseBinaryMatrix) bool {
+func (tp *TemporalPooler) isSegmentActive(seg Segment, activeState BinaryMatrix) bool {
 
 	if len(seg.syns) < tp.params.ActivationThreshold {
 		return false
@@ -531,12 +560,13 @@ func (tp *TemporalPooler) computeOutput() []bool {
 		// active cells
 
 		mostActiveCellPerCol := tp.DynamicState.CellConfidence.ArgMaxCols()
-		tp.CurrentOutput = NewSparseBinaryMatrix(tp.DynamicState.InfActiveState.Height, tp.DynamicState.InfActiveState.Width)
+		height, width := tp.DynamicState.InfActiveState.Dims()
+		tp.CurrentOutput = tp.newMatrix(height, width)
 
 		// Turn on the most confident cell in each column. Note here that
 		// Columns refers to TP columns, even though each TP column is a row
 		// in the matrix.
-		for i := 0; i < tp.CurrentOutput.Height; i++ {
+		for i := 0; i < height; i++ {
 			//only on active cols
 			if len(tp.DynamicState.InfActiveState.GetRowIndices(i)) != 0 {
 				tp.CurrentOutput.Set(i, mostActiveCellPerCol[i], true)
@@ -1172,7 +1202,7 @@ param minThreshold minimum number of synapses required
 
 returns tuple (cellIdx, segment, numActiveSynapses)
 */
-func (tp *TemporalPooler) getBestMatchingCell(c int, activeState *SparseBinaryMatrix, minThreshold int) (int, *Segment, int) {
+func (tp *TemporalPooler) getBestMatchingCell(c int, activeState BinaryMatrix, minThreshold int) (int, *Segment, int) {
 	// Collect all cells in column c that have at least minThreshold in the most
 	// activated segment
 	bestActivityInCol := minThreshold
@@ -1217,7 +1247,7 @@ func (tp *TemporalPooler) getBestMatchingCell(c int, activeState *SparseBinaryMa
  timeStep = t, and we cache that set of candidates.
 */
 func (tp *TemporalPooler) chooseCellsToLearnFrom(s *Segment, n int,
-	activeState *SparseBinaryMatrix) []SparseEntry {
+	activeState BinaryMatrix) []SparseEntry {
 	if n <= 0 {
 		return nil
 	}
@@ -1261,7 +1291,7 @@ func (tp *TemporalPooler) chooseCellsToLearnFrom(s *Segment, n int,
 
 	//if only one is required pick a random candidate
 	if n == 1 {
-		idx := rand.Intn(len(candidates))
+		idx := tp.rng.Intn(len(candidates))
 		return []SparseEntry{candidates[idx]} // col and cell idx in col
 	}
 
@@ -1270,7 +1300,7 @@ func (tp *TemporalPooler) chooseCellsToLearnFrom(s *Segment, n int,
 
 	//Shuffle candidates
 	for i := range candidates {
-		j := rand.Intn(i + 1)
+		j := tp.rng.Intn(i + 1)
 		candidates[i], candidates[j] = candidates[j], candidates[i]
 	}
 
@@ -1294,7 +1324,7 @@ func (tp *TemporalPooler) getCellForNewSegment(colIdx int) int {
 		i := 0
 		if tp.params.CellsPerColumn > 1 {
 			// Don't ever choose the start cell (cell # 0) in each column
-			i = rand.Intn(tp.params.CellsPerColumn-1) + 1
+			i = tp.rng.Intn(tp.params.CellsPerColumn-1) + 1
 		}
 		return i
 	}
@@ -1326,7 +1356,7 @@ func (tp *TemporalPooler) getCellForNewSegment(colIdx int) int {
 	// If we found one, return with it. Note we need to use _random to maintain
 	// correspondence with CPP code.
 	if len(candidateCellIdxs) > 0 {
-		cellIdx := rand.Intn(len(candidateCellIdxs))
+		cellIdx := tp.rng.Intn(len(candidateCellIdxs))
 		if tp.params.Verbosity >= 5 {
 			fmt.Printf("Cell [%v,%v] chosen for new segment, # of segs is %v \n",
 				colIdx, candidateCellIdxs[cellIdx], len(tp.cells[colIdx][cellIdx]))
@@ -1376,7 +1406,7 @@ permanence value of synapses is allowed to be below connectedPerm. The number
 of active synapses is allowed to be below activationThreshold, but must be
 above minThreshold. The routine returns the segment
 */
-func (tp *TemporalPooler) getBestMatchingSegment(c int, i int, activeState *SparseBinaryMatrix) *Segment {
+func (tp *TemporalPooler) getBestMatchingSegment(c int, i int, activeState BinaryMatrix) *Segment {
 	maxActivity := tp.params.MinThreshold
 	which := -1
 
@@ -1924,6 +1954,9 @@ func (tp *TemporalPooler) Compute(bottomUpInput []bool, enableLearn bool, comput
 	// Get the list of columns that have bottom-up
 	activeColumns := utils.OnIndices(bottomUpInput)
 
+	// Score the input against the predictions of the previous step
+	tp.updateAnomalyScore(activeColumns, computeInfOutput)
+
 	if enableLearn {
 		tp.lrnIterationIdx++
 	}
@@ -2025,7 +2058,7 @@ func (tp *TemporalPooler) Compute(bottomUpInput []bool, enableLearn bool, comput
 
 		// Update the prediction score stats
 		// Learning always includes inference
-		var predictedState *SparseBinaryMatrix
+		var predictedState BinaryMatrix
 		if tp.params.CollectStats {
 			if computeInfOutput {
 				predictedState = tp.DynamicState.InfPredictedStateLast.Copy()
@@ -2068,6 +2101,51 @@ func (tp *TemporalPooler) Compute(bottomUpInput []bool, enableLearn bool, comput
 
 }
 
+/*
+	Computes the output for an SDR of active columns, see Compute. The
+returned SDR has the number of columns and cells per column as dimensions.
+*/
+func (tp *TemporalPooler) ComputeSDR(bottomUpInput *SDR, enableLearn bool, computeInfOutput bool) *SDR {
+	output := tp.Compute(bottomUpInput.Dense(), enableLearn, computeInfOutput)
+	return NewSDRFromDense(output, tp.params.NumberOfCols, tp.params.CellsPerColumn)
+}
+
+/*
+	Records the active columns and the columns predicted for them by the
+previous step and computes the anomaly score according to AnomalyMode
+*/
+func (tp *TemporalPooler) updateAnomalyScore(activeColumns []int, computeInfOutput bool) {
+	if computeInfOutput {
+		tp.prevPredictedColumns = uniqueSortedColumns(tp.DynamicState.InfPredictedState.NonZeroRows())
+	} else {
+		tp.prevPredictedColumns = uniqueSortedColumns(tp.DynamicState.LrnPredictedState.NonZeroRows())
+	}
+	tp.lastActiveColumns = activeColumns
+
+	switch tp.params.AnomalyMode {
+	case ConfidenceWeightedAnomaly:
+		tp.anomalyScore = ComputeWeightedAnomalyScore(activeColumns, tp.prevPredictedColumns,
+			tp.DynamicState.ColConfidence)
+	default:
+		tp.anomalyScore = ComputeRawAnomalyScore(activeColumns, tp.prevPredictedColumns)
+	}
+}
+
+//Returns the anomaly score of the last compute, see AnomalyMode
+func (tp *TemporalPooler) AnomalyScore() float64 {
+	return tp.anomalyScore
+}
+
+//Returns the active columns of the last compute that were predicted
+func (tp *TemporalPooler) PredictedActiveColumns() []int {
+	return filterPredictedColumns(tp.lastActiveColumns, tp.prevPredictedColumns, true)
+}
+
+//Returns the active columns of the last compute that were not predicted
+func (tp *TemporalPooler) UnpredictedActiveColumns() []int {
+	return filterPredictedColumns(tp.lastActiveColumns, tp.prevPredictedColumns, false)
+}
+
 /*
 	 Reset the state of all cells.
 
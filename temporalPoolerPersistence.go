@@ -0,0 +1,515 @@
+package htm
+
+import (
+	"encoding/gob"
+	"fmt"
+	"github.com/nupic-community/htm/utils"
+	"github.com/zacg/go.matrix"
+	"io"
+	"math/rand"
+)
+
+//Version of the temporal pooler serialization format, bump when
+//temporalPoolerState changes in an incompatible way
+const temporalPoolerSerialVersion = 3
+
+//Serializable representation of a sparse binary matrix
+type sparseBinaryMatrixState struct {
+	Width   int
+	Height  int
+	Entries []SparseEntry
+}
+
+//Serializable representation of a dense float matrix
+type denseMatrixState struct {
+	Rows   int
+	Cols   int
+	Values []float64
+}
+
+//Serializable representation of a segment, the tp back-pointer is
+//re-established on load
+type segmentState struct {
+	SegId                     int
+	IsSequenceSeg             bool
+	LastActiveIteration       int
+	PositiveActivations       int
+	TotalActivations          int
+	LastPosDutyCycle          float64
+	LastPosDutyCycleIteration int
+	Syns                      []Synapse
+}
+
+/*
+Serializable representation of a pending segment update. Segment updates
+point into the cells of the pooler, so the segment is stored as its index
+within the cell. Updates pointing at a segment no longer in the cell
+(or a new segment when nil) store a detached copy.
+*/
+type updateStateState struct {
+	Column           int
+	Cell             int
+	CreationDate     int
+	ColumnIdx        int
+	CellIdx          int
+	SegmentIdx       int
+	Detached         *segmentState
+	ActiveSynapses   []SynapseUpdateState
+	SequenceSegment  bool
+	Phase1Flag       bool
+	WeaklyPredicting bool
+	LrnIterationIdx  int
+}
+
+//Serializable representation of the dynamic state
+type dynamicStateState struct {
+	LrnActiveState     *sparseBinaryMatrixState
+	LrnActiveStateLast *sparseBinaryMatrixState
+
+	LrnPredictedState     *sparseBinaryMatrixState
+	LrnPredictedStateLast *sparseBinaryMatrixState
+
+	InfActiveState          *sparseBinaryMatrixState
+	InfActiveStateLast      *sparseBinaryMatrixState
+	InfActiveStateBackup    *sparseBinaryMatrixState
+	InfActiveStateCandidate *sparseBinaryMatrixState
+
+	InfPredictedState          *sparseBinaryMatrixState
+	InfPredictedStateLast      *sparseBinaryMatrixState
+	InfPredictedStateBackup    *sparseBinaryMatrixState
+	InfPredictedStateCandidate *sparseBinaryMatrixState
+
+	CellConfidence          *denseMatrixState
+	CellConfidenceLast      *denseMatrixState
+	CellConfidenceCandidate *denseMatrixState
+
+	ColConfidence          []float64
+	ColConfidenceLast      []float64
+	ColConfidenceCandidate []float64
+}
+
+/*
+Serializable snapshot of a temporal pooler. Collected statistics are
+diagnostic only and are not persisted. The trivial predictor state is nil
+when no trivial prediction methods are configured.
+*/
+type temporalPoolerState struct {
+	SerialVersion int
+	Params        TemporalPoolerParams
+	OutputType    TpOutputType
+	//Representation of the dynamic state matrices, empty for the default
+	Matrix string
+
+	NumberOfCells        int
+	ActiveColumns        []int
+	Cells                [][][]segmentState
+	LrnIterationIdx      int
+	IterationIdx         int
+	SegId                int
+	CurrentOutput        *sparseBinaryMatrixState
+	PamCounter           int
+	AvgInputDensity      float64
+	AvgLearnedSeqLength  float64
+	ResetCalled          bool
+	LearnedSeqLength     int
+	CollectSequenceStats bool
+
+	SegmentUpdates  []updateStateState
+	PrevInfPatterns [][]int
+	PrevLrnPatterns [][]int
+
+	LastActiveColumns    []int
+	PrevPredictedColumns []int
+	AnomalyScore         float64
+
+	DynamicState *dynamicStateState
+
+	Rand utils.RandState
+
+	TrivialPredictor *trivialPredictorSnapshot
+}
+
+//Serializable representation of a trivial predictor, its methods are
+//restored from the pooler params
+type trivialPredictorSnapshot struct {
+	State          map[PredictorMethod]TrivialPredictorState
+	ColumnCount    []int
+	AverageDensity float64
+	Rand           utils.RandState
+}
+
+func newTrivialPredictorSnapshot(tp *TrivialPredictor) *trivialPredictorSnapshot {
+	if tp == nil {
+		return nil
+	}
+	result := new(trivialPredictorSnapshot)
+	result.State = tp.State
+	result.ColumnCount = tp.ColumnCount
+	result.AverageDensity = tp.AverageDensity
+	result.Rand = tp.rngSource.State()
+	return result
+}
+
+//Restores the state of a trivial predictor created from the pooler params
+func (s *trivialPredictorSnapshot) restore(tp *TrivialPredictor) error {
+	if s == nil {
+		return fmt.Errorf("temporal pooler state is missing the trivial predictor")
+	}
+	if len(s.ColumnCount) != tp.NumOfCols {
+		return fmt.Errorf("trivial predictor state does not match %v columns", tp.NumOfCols)
+	}
+	for _, method := range tp.Methods {
+		ms, ok := s.State[method]
+		if !ok {
+			return fmt.Errorf("trivial predictor state is missing method %v", method)
+		}
+		if len(ms.ActiveState) != tp.NumOfCols || len(ms.ActiveStateLast) != tp.NumOfCols ||
+			len(ms.PredictedState) != tp.NumOfCols || len(ms.PredictedStateLast) != tp.NumOfCols ||
+			len(ms.Confidence) != tp.NumOfCols || len(ms.ConfidenceLast) != tp.NumOfCols {
+			return fmt.Errorf("trivial predictor state of method %v does not match %v columns", method, tp.NumOfCols)
+		}
+		tp.State[method] = ms
+	}
+	rngSource, err := utils.NewRandSourceFromState(s.Rand)
+	if err != nil {
+		return err
+	}
+	tp.ColumnCount = s.ColumnCount
+	tp.AverageDensity = s.AverageDensity
+	tp.rngSource = rngSource
+	tp.rng = rand.New(tp.rngSource)
+	return nil
+}
+
+func newSparseBinaryMatrixState(sm BinaryMatrix) *sparseBinaryMatrixState {
+	if sm == nil {
+		return nil
+	}
+	result := new(sparseBinaryMatrixState)
+	result.Height, result.Width = sm.Dims()
+	result.Entries = sm.Entries()
+	return result
+}
+
+func (s *sparseBinaryMatrixState) matrix(newMatrix BinaryMatrixFactory) BinaryMatrix {
+	if s == nil {
+		return nil
+	}
+	result := newMatrix(s.Height, s.Width)
+	for _, entry := range s.Entries {
+		result.Set(entry.Row, entry.Col, true)
+	}
+	return result
+}
+
+func newDenseMatrixState(m *matrix.DenseMatrix) *denseMatrixState {
+	if m == nil {
+		return nil
+	}
+	result := new(denseMatrixState)
+	result.Rows = m.Rows()
+	result.Cols = m.Cols()
+	result.Values = m.Array()
+	return result
+}
+
+func (s *denseMatrixState) matrix() *matrix.DenseMatrix {
+	if s == nil {
+		return nil
+	}
+	values := make([]float64, s.Rows*s.Cols)
+	copy(values, s.Values)
+	return matrix.MakeDenseMatrix(values, s.Rows, s.Cols)
+}
+
+func newSegmentState(seg *Segment) *segmentState {
+	result := new(segmentState)
+	result.SegId = seg.segId
+	result.IsSequenceSeg = seg.isSequenceSeg
+	result.LastActiveIteration = seg.lastActiveIteration
+	result.PositiveActivations = seg.positiveActivations
+	result.TotalActivations = seg.totalActivations
+	result.LastPosDutyCycle = seg.lastPosDutyCycle
+	result.LastPosDutyCycleIteration = seg.lastPosDutyCycleIteration
+	result.Syns = seg.syns
+	return result
+}
+
+func (s *segmentState) segment(tp *TemporalPooler) Segment {
+	result := Segment{}
+	result.tp = tp
+	result.segId = s.SegId
+	result.isSequenceSeg = s.IsSequenceSeg
+	result.lastActiveIteration = s.LastActiveIteration
+	result.positiveActivations = s.PositiveActivations
+	result.totalActivations = s.TotalActivations
+	result.lastPosDutyCycle = s.LastPosDutyCycle
+	result.lastPosDutyCycleIteration = s.LastPosDutyCycleIteration
+	result.syns = make([]Synapse, len(s.Syns))
+	copy(result.syns, s.Syns)
+	return result
+}
+
+func newDynamicStateState(ds *DynamicState) *dynamicStateState {
+	if ds == nil {
+		return nil
+	}
+	result := new(dynamicStateState)
+	result.LrnActiveState = newSparseBinaryMatrixState(ds.LrnActiveState)
+	result.LrnActiveStateLast = newSparseBinaryMatrixState(ds.LrnActiveStateLast)
+	result.LrnPredictedState = newSparseBinaryMatrixState(ds.LrnPredictedState)
+	result.LrnPredictedStateLast = newSparseBinaryMatrixState(ds.LrnPredictedStateLast)
+
+	result.InfActiveState = newSparseBinaryMatrixState(ds.InfActiveState)
+	result.InfActiveStateLast = newSparseBinaryMatrixState(ds.InfActiveStateLast)
+	result.InfActiveStateBackup = newSparseBinaryMatrixState(ds.InfActiveStateBackup)
+	result.InfActiveStateCandidate = newSparseBinaryMatrixState(ds.InfActiveStateCandidate)
+
+	result.InfPredictedState = newSparseBinaryMatrixState(ds.InfPredictedState)
+	result.InfPredictedStateLast = newSparseBinaryMatrixState(ds.InfPredictedStateLast)
+	result.InfPredictedStateBackup = newSparseBinaryMatrixState(ds.InfPredictedStateBackup)
+	result.InfPredictedStateCandidate = newSparseBinaryMatrixState(ds.InfPredictedStateCandidate)
+
+	result.CellConfidence = newDenseMatrixState(ds.CellConfidence)
+	result.CellConfidenceLast = newDenseMatrixState(ds.CellConfidenceLast)
+	result.CellConfidenceCandidate = newDenseMatrixState(ds.CellConfidenceCandidate)
+
+	result.ColConfidence = ds.ColConfidence
+	result.ColConfidenceLast = ds.ColConfidenceLast
+	result.ColConfidenceCandidate = ds.ColConfidenceCandidate
+	return result
+}
+
+func (s *dynamicStateState) dynamicState(newMatrix BinaryMatrixFactory) *DynamicState {
+	if s == nil {
+		return nil
+	}
+	result := new(DynamicState)
+	result.LrnActiveState = s.LrnActiveState.matrix(newMatrix)
+	result.LrnActiveStateLast = s.LrnActiveStateLast.matrix(newMatrix)
+	result.LrnPredictedState = s.LrnPredictedState.matrix(newMatrix)
+	result.LrnPredictedStateLast = s.LrnPredictedStateLast.matrix(newMatrix)
+
+	result.InfActiveState = s.InfActiveState.matrix(newMatrix)
+	result.InfActiveStateLast = s.InfActiveStateLast.matrix(newMatrix)
+	result.InfActiveStateBackup = s.InfActiveStateBackup.matrix(newMatrix)
+	result.InfActiveStateCandidate = s.InfActiveStateCandidate.matrix(newMatrix)
+
+	result.InfPredictedState = s.InfPredictedState.matrix(newMatrix)
+	result.InfPredictedStateLast = s.InfPredictedStateLast.matrix(newMatrix)
+	result.InfPredictedStateBackup = s.InfPredictedStateBackup.matrix(newMatrix)
+	result.InfPredictedStateCandidate = s.InfPredictedStateCandidate.matrix(newMatrix)
+
+	result.CellConfidence = s.CellConfidence.matrix()
+	result.CellConfidenceLast = s.CellConfidenceLast.matrix()
+	result.CellConfidenceCandidate = s.CellConfidenceCandidate.matrix()
+
+	result.ColConfidence = s.ColConfidence
+	result.ColConfidenceLast = s.ColConfidenceLast
+	result.ColConfidenceCandidate = s.ColConfidenceCandidate
+	return result
+}
+
+//Returns the index of the specified segment within its cell, -1 if
+//the segment is not part of the cell
+func (tp *TemporalPooler) segmentIndex(c, i int, seg *Segment) int {
+	for idx := range tp.cells[c][i] {
+		if &tp.cells[c][i][idx] == seg {
+			return idx
+		}
+	}
+	return -1
+}
+
+//Writes the complete state of the temporal pooler, including segments,
+//pending segment updates and ephemeral state to w. The pooler can be
+//restored with LoadTemporalPooler.
+func (tp *TemporalPooler) Save(w io.Writer) error {
+	state := temporalPoolerState{}
+	state.SerialVersion = temporalPoolerSerialVersion
+	state.Params = tp.params
+	state.OutputType = tp.params.outputType
+	if tp.params.MatrixFactory != nil {
+		state.Matrix = matrixRepresentation(tp.params.MatrixFactory(1, 1))
+	}
+
+	state.NumberOfCells = tp.numberOfCells
+	state.ActiveColumns = tp.activeColumns
+	state.LrnIterationIdx = tp.lrnIterationIdx
+	state.IterationIdx = tp.iterationIdx
+	state.SegId = tp.segId
+	state.CurrentOutput = newSparseBinaryMatrixState(tp.CurrentOutput)
+	state.PamCounter = tp.pamCounter
+	state.AvgInputDensity = tp.avgInputDensity
+	state.AvgLearnedSeqLength = tp.avgLearnedSeqLength
+	state.ResetCalled = tp.resetCalled
+	state.LearnedSeqLength = tp.learnedSeqLength
+	state.CollectSequenceStats = tp.collectSequenceStats
+
+	state.Cells = make([][][]segmentState, len(tp.cells))
+	for c := range tp.cells {
+		state.Cells[c] = make([][]segmentState, len(tp.cells[c]))
+		for i := range tp.cells[c] {
+			state.Cells[c][i] = make([]segmentState, len(tp.cells[c][i]))
+			for idx := range tp.cells[c][i] {
+				state.Cells[c][i][idx] = *newSegmentState(&tp.cells[c][i][idx])
+			}
+		}
+	}
+
+	for key, updates := range tp.segmentUpdates {
+		for _, val := range updates {
+			us := updateStateState{}
+			us.Column = key.A
+			us.Cell = key.B
+			us.CreationDate = val.CreationDate
+			us.SegmentIdx = -1
+			if ud := val.Update; ud != nil {
+				us.ColumnIdx = ud.columnIdx
+				us.CellIdx = ud.cellIdx
+				if ud.segment != nil {
+					us.SegmentIdx = tp.segmentIndex(ud.columnIdx, ud.cellIdx, ud.segment)
+					if us.SegmentIdx < 0 {
+						us.Detached = newSegmentState(ud.segment)
+					}
+				}
+				us.ActiveSynapses = ud.activeSynapses
+				us.SequenceSegment = ud.sequenceSegment
+				us.Phase1Flag = ud.phase1Flag
+				us.WeaklyPredicting = ud.weaklyPredicting
+				us.LrnIterationIdx = ud.lrnIterationIdx
+			}
+			state.SegmentUpdates = append(state.SegmentUpdates, us)
+		}
+	}
+
+	state.PrevInfPatterns = tp.prevInfPatterns
+	state.PrevLrnPatterns = tp.prevLrnPatterns
+	state.LastActiveColumns = tp.lastActiveColumns
+	state.PrevPredictedColumns = tp.prevPredictedColumns
+	state.AnomalyScore = tp.anomalyScore
+	state.DynamicState = newDynamicStateState(tp.DynamicState)
+	state.Rand = tp.rngSource.State()
+	state.TrivialPredictor = newTrivialPredictorSnapshot(tp.trivialPredictor)
+
+	return gob.NewEncoder(w).Encode(&state)
+}
+
+//Returns true if the pooler has the specified cell
+func (tp *TemporalPooler) containsCell(col int, cell int) bool {
+	return col >= 0 && col < tp.params.NumberOfCols &&
+		cell >= 0 && cell < tp.params.CellsPerColumn
+}
+
+/*
+	Restores a temporal pooler previously written with Save. The dynamic
+state matrices are created with newMatrix, or with the representation of
+the saved pooler when nil. Poolers saved with a custom representation
+require newMatrix.
+*/
+func LoadTemporalPooler(r io.Reader, newMatrix BinaryMatrixFactory) (*TemporalPooler, error) {
+	state := temporalPoolerState{}
+	if err := gob.NewDecoder(r).Decode(&state); err != nil {
+		return nil, err
+	}
+	if state.SerialVersion != temporalPoolerSerialVersion {
+		return nil, fmt.Errorf("unsupported temporal pooler version %v", state.SerialVersion)
+	}
+	if len(state.Cells) != state.Params.NumberOfCols {
+		return nil, fmt.Errorf("temporal pooler state does not match %v columns", state.Params.NumberOfCols)
+	}
+
+	tp := new(TemporalPooler)
+	tp.params = state.Params
+	tp.params.outputType = state.OutputType
+	if state.Matrix != "" || newMatrix != nil {
+		factory, err := snapshotMatrixFactory(state.Matrix, newMatrix)
+		if err != nil {
+			return nil, err
+		}
+		tp.params.MatrixFactory = factory
+	}
+
+	tp.numberOfCells = state.NumberOfCells
+	tp.activeColumns = state.ActiveColumns
+	tp.lrnIterationIdx = state.LrnIterationIdx
+	tp.iterationIdx = state.IterationIdx
+	tp.segId = state.SegId
+	tp.CurrentOutput = state.CurrentOutput.matrix(tp.newMatrix)
+	tp.pamCounter = state.PamCounter
+	tp.avgInputDensity = state.AvgInputDensity
+	tp.avgLearnedSeqLength = state.AvgLearnedSeqLength
+	tp.resetCalled = state.ResetCalled
+	tp.learnedSeqLength = state.LearnedSeqLength
+	tp.collectSequenceStats = state.CollectSequenceStats
+
+	tp.cells = make([][][]Segment, tp.params.NumberOfCols)
+	for c := range tp.cells {
+		if len(state.Cells[c]) != tp.params.CellsPerColumn {
+			return nil, fmt.Errorf("temporal pooler state does not match %v cells per column", tp.params.CellsPerColumn)
+		}
+		tp.cells[c] = make([][]Segment, tp.params.CellsPerColumn)
+		for i := range tp.cells[c] {
+			for _, seg := range state.Cells[c][i] {
+				tp.cells[c][i] = append(tp.cells[c][i], seg.segment(tp))
+			}
+		}
+	}
+
+	for _, us := range state.SegmentUpdates {
+		if !tp.containsCell(us.Column, us.Cell) {
+			return nil, fmt.Errorf("segment update keyed by missing cell %v,%v", us.Column, us.Cell)
+		}
+		if !tp.containsCell(us.ColumnIdx, us.CellIdx) {
+			return nil, fmt.Errorf("segment update references missing cell %v,%v", us.ColumnIdx, us.CellIdx)
+		}
+		ud := new(SegmentUpdate)
+		ud.columnIdx = us.ColumnIdx
+		ud.cellIdx = us.CellIdx
+		if us.SegmentIdx >= 0 {
+			if us.SegmentIdx >= len(tp.cells[us.ColumnIdx][us.CellIdx]) {
+				return nil, fmt.Errorf("segment update references missing segment %v", us.SegmentIdx)
+			}
+			ud.segment = &tp.cells[us.ColumnIdx][us.CellIdx][us.SegmentIdx]
+		} else if us.Detached != nil {
+			seg := us.Detached.segment(tp)
+			ud.segment = &seg
+		}
+		ud.activeSynapses = us.ActiveSynapses
+		ud.sequenceSegment = us.SequenceSegment
+		ud.phase1Flag = us.Phase1Flag
+		ud.weaklyPredicting = us.WeaklyPredicting
+		ud.lrnIterationIdx = us.LrnIterationIdx
+
+		if tp.segmentUpdates == nil {
+			tp.segmentUpdates = make(map[utils.TupleInt][]UpdateState, 1000)
+		}
+		key := utils.TupleInt{}
+		key.A = us.Column
+		key.B = us.Cell
+		tp.segmentUpdates[key] = append(tp.segmentUpdates[key], UpdateState{us.CreationDate, ud})
+	}
+
+	tp.prevInfPatterns = state.PrevInfPatterns
+	tp.prevLrnPatterns = state.PrevLrnPatterns
+	tp.lastActiveColumns = state.LastActiveColumns
+	tp.prevPredictedColumns = state.PrevPredictedColumns
+	tp.anomalyScore = state.AnomalyScore
+	tp.DynamicState = state.DynamicState.dynamicState(tp.newMatrix)
+	rngSource, err := utils.NewRandSourceFromState(state.Rand)
+	if err != nil {
+		return nil, err
+	}
+	tp.rngSource = rngSource
+	tp.rng = rand.New(tp.rngSource)
+
+	if len(tp.params.TrivialPredictionMethods) > 0 {
+		tp.trivialPredictor = MakeTrivialPredictor(tp.params.NumberOfCols, tp.params.TrivialPredictionMethods, tp.params.Seed)
+		if err := state.TrivialPredictor.restore(tp.trivialPredictor); err != nil {
+			return nil, err
+		}
+	}
+	tp.internalStats = new(TpStats)
+
+	return tp, nil
+}
@@ -0,0 +1,203 @@
+package htm
+
+import (
+	"bytes"
+	"encoding/gob"
+	"github.com/zacg/testify/assert"
+	"testing"
+)
+
+func TestTemporalPoolerSaveLoad(t *testing.T) {
+	tps := NewTemporalPoolerParams()
+	tps.Verbosity = 0
+	tps.NumberOfCols = 50
+	tps.CellsPerColumn = 2
+	tps.ActivationThreshold = 8
+	tps.MinThreshold = 10
+	tps.InitialPerm = 0.5
+	tps.ConnectedPerm = 0.5
+	tps.NewSynapseCount = 10
+	tps.PermanenceDec = 0.0
+	tps.PermanenceInc = 0.1
+	tps.GlobalDecay = 0
+	tps.BurnIn = 1
+	tps.PamLength = 10
+	tp := NewTemporalPooler(*tps)
+
+	inputs := make([][]bool, 5)
+	inputs[0] = boolRange(0, 9, 50)
+	inputs[1] = boolRange(10, 19, 50)
+	inputs[2] = boolRange(20, 29, 50)
+	inputs[3] = boolRange(30, 39, 50)
+	inputs[4] = boolRange(40, 49, 50)
+
+	for i := 0; i < 10; i++ {
+		for p := 0; p < 5; p++ {
+			tp.Compute(inputs[p], true, false)
+		}
+		tp.Reset()
+	}
+
+	// Stop mid sequence
+	tp.Compute(inputs[0], false, true)
+	tp.Compute(inputs[1], false, true)
+
+	var buf bytes.Buffer
+	err := tp.Save(&buf)
+	assert.Equal(t, nil, err)
+
+	tp2, err := LoadTemporalPooler(&buf, nil)
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, tp.params, tp2.params)
+	assert.Equal(t, tp.lrnIterationIdx, tp2.lrnIterationIdx)
+	assert.Equal(t, tp.iterationIdx, tp2.iterationIdx)
+	assert.Equal(t, tp.pamCounter, tp2.pamCounter)
+	assert.Equal(t, tp.segId, tp2.segId)
+	assert.Equal(t, tp.prevInfPatterns, tp2.prevInfPatterns)
+
+	for c := range tp.cells {
+		for i := range tp.cells[c] {
+			assert.Equal(t, len(tp.cells[c][i]), len(tp2.cells[c][i]))
+			for idx := range tp2.cells[c][i] {
+				seg := tp2.cells[c][i][idx]
+				assert.True(t, seg.tp == tp2)
+				seg.tp = tp
+				assert.True(t, seg.Equals(&tp.cells[c][i][idx]))
+			}
+		}
+	}
+
+	assert.Equal(t, tp.DynamicState.InfPredictedState.Flatten(),
+		tp2.DynamicState.InfPredictedState.Flatten())
+	assert.Equal(t, tp.DynamicState.ColConfidence, tp2.DynamicState.ColConfidence)
+
+	// Resuming the sequence should give the same predictions
+	for p := 2; p < 5; p++ {
+		out1 := tp.Compute(inputs[p], false, true)
+		out2 := tp2.Compute(inputs[p], false, true)
+		assert.Equal(t, out1, out2)
+		assert.Equal(t, tp.DynamicState.InfPredictedState.Flatten(),
+			tp2.DynamicState.InfPredictedState.Flatten())
+	}
+
+}
+
+func TestTemporalPoolerSaveLoadTrivialPredictor(t *testing.T) {
+	tps := NewTemporalPoolerParams()
+	tps.Verbosity = 0
+	tps.NumberOfCols = 50
+	tps.CellsPerColumn = 2
+	tps.ActivationThreshold = 8
+	tps.MinThreshold = 10
+	tps.InitialPerm = 0.5
+	tps.ConnectedPerm = 0.5
+	tps.NewSynapseCount = 10
+	tps.PermanenceDec = 0.0
+	tps.PermanenceInc = 0.1
+	tps.GlobalDecay = 0
+	tps.BurnIn = 1
+	tps.PamLength = 10
+	tps.CollectStats = true
+	tps.TrivialPredictionMethods = []PredictorMethod{Random, Last}
+	tp := NewTemporalPooler(*tps)
+
+	inputs := make([][]bool, 3)
+	inputs[0] = boolRange(0, 9, 50)
+	inputs[1] = boolRange(10, 19, 50)
+	inputs[2] = boolRange(20, 29, 50)
+	for i := 0; i < 3; i++ {
+		for p := range inputs {
+			tp.Compute(inputs[p], true, true)
+		}
+	}
+
+	var buf bytes.Buffer
+	assert.Equal(t, nil, tp.Save(&buf))
+	tp2, err := LoadTemporalPooler(&buf, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, tp.trivialPredictor.AverageDensity, tp2.trivialPredictor.AverageDensity)
+	assert.Equal(t, tp.trivialPredictor.ColumnCount, tp2.trivialPredictor.ColumnCount)
+
+	// The random predictor continues its sequence instead of restarting it
+	for p := range inputs {
+		tp.Compute(inputs[p], true, true)
+		tp2.Compute(inputs[p], true, true)
+		for _, method := range tps.TrivialPredictionMethods {
+			assert.Equal(t, tp.trivialPredictor.State[method], tp2.trivialPredictor.State[method])
+		}
+	}
+
+	fresh := MakeTrivialPredictor(tps.NumberOfCols, tps.TrivialPredictionMethods, tps.Seed)
+	fresh.learn([]int{0})
+	assert.NotEqual(t, fresh.State[Random].PredictedState, tp2.trivialPredictor.State[Random].PredictedState)
+}
+
+func TestTemporalPoolerSaveLoadMatrixRepresentation(t *testing.T) {
+	tps := NewTemporalPoolerParams()
+	tps.NumberOfCols = 20
+	tps.CellsPerColumn = 2
+	tps.MatrixFactory = BitsetMatrixFactory
+	tp := NewTemporalPooler(*tps)
+	tp.Compute(boolRange(0, 4, 20), false, true)
+
+	var buf bytes.Buffer
+	assert.Equal(t, nil, tp.Save(&buf))
+	saved := buf.Bytes()
+	tp2, err := LoadTemporalPooler(bytes.NewBuffer(saved), nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "bitset", matrixRepresentation(tp2.CurrentOutput))
+	assert.Equal(t, "bitset", matrixRepresentation(tp2.DynamicState.InfActiveState))
+	assert.Equal(t, "bitset", matrixRepresentation(tp2.newMatrix(1, 1)))
+
+	// an explicit factory overrides the saved representation
+	tp2, err = LoadTemporalPooler(bytes.NewBuffer(saved), CSRMatrixFactory)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "csr", matrixRepresentation(tp2.DynamicState.InfActiveState))
+
+	// the default representation stays the default
+	tps.MatrixFactory = nil
+	tp = NewTemporalPooler(*tps)
+	buf.Reset()
+	assert.Equal(t, nil, tp.Save(&buf))
+	tp2, err = LoadTemporalPooler(&buf, nil)
+	assert.Equal(t, nil, err)
+	assert.True(t, tp2.params.MatrixFactory == nil)
+	assert.Equal(t, "sparse", matrixRepresentation(tp2.DynamicState.InfActiveState))
+}
+
+func TestLoadTemporalPoolerInvalid(t *testing.T) {
+	_, err := LoadTemporalPooler(bytes.NewBufferString("garbage"), nil)
+	assert.True(t, err != nil)
+}
+
+func TestLoadTemporalPoolerInvalidSegmentUpdate(t *testing.T) {
+	tps := NewTemporalPoolerParams()
+	tps.NumberOfCols = 10
+	tps.CellsPerColumn = 2
+	tp := NewTemporalPooler(*tps)
+
+	var buf bytes.Buffer
+	assert.Equal(t, nil, tp.Save(&buf))
+	saved := buf.Bytes()
+
+	tampers := []func(us *updateStateState){
+		func(us *updateStateState) { us.Column = 10 },
+		func(us *updateStateState) { us.Cell = -1 },
+		func(us *updateStateState) { us.ColumnIdx = 11 },
+		func(us *updateStateState) { us.CellIdx = 2 },
+	}
+
+	for _, tamper := range tampers {
+		state := temporalPoolerState{}
+		assert.Equal(t, nil, gob.NewDecoder(bytes.NewReader(saved)).Decode(&state))
+		us := updateStateState{SegmentIdx: -1}
+		tamper(&us)
+		state.SegmentUpdates = append(state.SegmentUpdates, us)
+
+		var tampered bytes.Buffer
+		assert.Equal(t, nil, gob.NewEncoder(&tampered).Encode(&state))
+		_, err := LoadTemporalPooler(&tampered, nil)
+		assert.True(t, err != nil)
+	}
+}
@@ -112,7 +112,7 @@ func (tp *TemporalPooler) calcSegmentStats(collectActiveData bool) SegmentStats
  Print the list of [column, cellIdx] indices for each of the active
 cells in state.
 */
-func (tp *TemporalPooler) printActiveIndices(state *SparseBinaryMatrix, andValues bool) {
+func (tp *TemporalPooler) printActiveIndices(state BinaryMatrix, andValues bool) {
 	if state.TotalNonZeroCount() == 0 {
 		fmt.Println("None")
 		return
@@ -190,10 +190,11 @@ func (tp *TemporalPooler) printComputeEnd(output []bool, learn bool) {
 	fmt.Println("----- computeEnd summary: ")
 	fmt.Println("learn:", learn)
 	bursting := 0
-	counts := make([]int, tp.DynamicState.InfActiveState.Height)
+	height, width := tp.DynamicState.InfActiveState.Dims()
+	counts := make([]int, height)
 	for _, val := range tp.DynamicState.InfActiveState.Entries() {
 		counts[val.Row]++
-		if counts[val.Row] == tp.DynamicState.InfActiveState.Width {
+		if counts[val.Row] == width {
 			bursting++
 		}
 	}
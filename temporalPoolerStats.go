@@ -107,7 +107,7 @@ retval missing_i the bits in the i'th pattern that were missing
 in the output. This list is only returned if details is
 True.
 */
-func (tp *TemporalPooler) checkPrediction2(patternNZs [][]int, output *SparseBinaryMatrix,
+func (tp *TemporalPooler) checkPrediction2(patternNZs [][]int, output BinaryMatrix,
 	colConfidence []float64, details bool) (int, int, []confidence, []int) {
 
 	// Get the non-zeros in each pattern
@@ -243,7 +243,7 @@ param colConfidence Column confidences we determined on the last time step
 */
 
 func (tp *TemporalPooler) updateStatsInferEnd(stats *TpStats, bottomUpNZ []int,
-	predictedState *SparseBinaryMatrix, colConfidence []float64) {
+	predictedState BinaryMatrix, colConfidence []float64) {
 	// Return if not collecting stats
 	if !tp.params.CollectStats {
 		return
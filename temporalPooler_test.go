@@ -88,3 +88,25 @@ func GenerateRandSequence(size int, width int) []bool {
 
 	return input
 }
+
+func TestTemporalPoolerParamsValidate(t *testing.T) {
+	tps := NewTemporalPoolerParams()
+	assert.True(t, tps.Validate() == nil)
+
+	tps.PamLength = 0
+	err, ok := tps.Validate().(*ParamError)
+	assert.True(t, ok)
+	assert.Equal(t, "PamLength", err.Field)
+
+	tps = NewTemporalPoolerParams()
+	tps.MaxSegmentsPerCell = 10
+	tps.MaxSynapsesPerSegment = 5
+	tps.GlobalDecay = 0
+	tps.MaxAge = 0
+	err, ok = tps.Validate().(*ParamError)
+	assert.True(t, ok)
+	assert.Equal(t, "MaxSynapsesPerSegment", err.Field)
+
+	tps.MaxSynapsesPerSegment = tps.NewSynapseCount
+	assert.True(t, tps.Validate() == nil)
+}
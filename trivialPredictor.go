@@ -6,7 +6,7 @@ import (
 	"github.com/nupic-community/htm/utils"
 	//"github.com/skelterjohn/go.matrix"
 	//"math"
-	//"math/rand"
+	"math/rand"
 	//"sort"
 	//"github.com/gonum/floats"
 	"github.com/zacg/ints"
@@ -50,10 +50,18 @@ type TrivialPredictor struct {
 	State          map[PredictorMethod]TrivialPredictorState
 	ColumnCount    []int
 	AverageDensity float64
+	rng            *rand.Rand
+	rngSource      *utils.RandSource
 }
 
-func MakeTrivialPredictor(numberOfCols int, methods []PredictorMethod) *TrivialPredictor {
+func MakeTrivialPredictor(numberOfCols int, methods []PredictorMethod, seed int) *TrivialPredictor {
 	tp := new(TrivialPredictor)
+	tp.NumOfCols = numberOfCols
+	tp.Methods = methods
+	tp.InternalStats = make(map[PredictorMethod]*TpStats, len(methods))
+	tp.State = make(map[PredictorMethod]TrivialPredictorState, len(methods))
+	tp.rngSource = utils.NewRandSource(int64(seed))
+	tp.rng = rand.New(tp.rngSource)
 
 	for _, method := range methods {
 		tps := TrivialPredictorState{}
@@ -105,7 +113,7 @@ func (tp *TrivialPredictor) infer(activeColumns []int) {
 		switch method {
 		case Random:
 			// Randomly predict N columns
-			//predictedCols = RandomInts(numColsToPredict, tp.NumOfCols)
+			predictedCols = tp.rng.Perm(tp.NumOfCols)[:numColsToPredict]
 			break
 		case Zeroth:
 			// Always predict the top N most frequent columns
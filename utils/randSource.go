@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+const (
+	randLen  = 607
+	randTap  = 273
+	randMask = 1<<63 - 1
+)
+
+//Position of a RandSource in its random sequence
+type RandState struct {
+	Seed int64
+	Tap  int
+	Feed int
+	Vec  []int64
+}
+
+/*
+ Deterministic random source producing the same sequence as the sources
+in math/rand for the same seed. It uses the same additive lagged fibonacci
+generator, but keeps the generator state accessible so it can be saved and
+restored directly.
+*/
+type RandSource struct {
+	seed int64
+	tap  int
+	feed int
+	vec  [randLen]int64
+}
+
+//Creates a new random source, negative seeds are replaced with a
+//time based seed
+func NewRandSource(seed int64) *RandSource {
+	rs := new(RandSource)
+	rs.Seed(seed)
+	return rs
+}
+
+//Creates a random source positioned at the specified state, returns an
+//error if the state is corrupt
+func NewRandSourceFromState(state RandState) (*RandSource, error) {
+	if len(state.Vec) != randLen {
+		return nil, fmt.Errorf("random state has %v values, expected %v", len(state.Vec), randLen)
+	}
+	if state.Tap < 0 || state.Tap >= randLen || state.Feed < 0 || state.Feed >= randLen ||
+		(state.Feed-state.Tap+randLen)%randLen != randLen-randTap {
+		return nil, fmt.Errorf("random state has invalid tap %v and feed %v", state.Tap, state.Feed)
+	}
+
+	rs := new(RandSource)
+	rs.seed = state.Seed
+	rs.tap = state.Tap
+	rs.feed = state.Feed
+	copy(rs.vec[:], state.Vec)
+	return rs, nil
+}
+
+//Returns a pseudo-random 64-bit value
+func (rs *RandSource) Uint64() uint64 {
+	rs.tap--
+	if rs.tap < 0 {
+		rs.tap += randLen
+	}
+	rs.feed--
+	if rs.feed < 0 {
+		rs.feed += randLen
+	}
+
+	x := rs.vec[rs.feed] + rs.vec[rs.tap]
+	rs.vec[rs.feed] = x
+	return uint64(x)
+}
+
+//Returns a non-negative pseudo-random 63-bit integer
+func (rs *RandSource) Int63() int64 {
+	return int64(rs.Uint64() & randMask)
+}
+
+/*
+	Reseeds the source and resets its position. The seeded generator state
+is recovered from the first values of a math/rand source: every value is
+the sum of the values randLen and randTap positions before it, so the
+initial state follows from randLen values by subtraction.
+*/
+func (rs *RandSource) Seed(seed int64) {
+	if seed < 0 {
+		seed = time.Now().UnixNano()
+	}
+	rs.seed = seed
+	rs.tap = 0
+	rs.feed = randLen - randTap
+
+	src := rand.NewSource(seed).(rand.Source64)
+	var values [randLen]int64
+	for i := range values {
+		values[i] = int64(src.Uint64())
+	}
+
+	//value i was written to position feed-1-i, adding the position tap-1-i
+	last := rs.feed - 1
+	for i := randTap; i < randLen; i++ {
+		rs.vec[(last-i+randLen)%randLen] = values[i] - values[i-randTap]
+	}
+	for i := 0; i < randTap; i++ {
+		rs.vec[last-i] = values[i] - rs.vec[randLen-1-i]
+	}
+}
+
+//Returns the current position of the source
+func (rs *RandSource) State() RandState {
+	state := RandState{Seed: rs.seed, Tap: rs.tap, Feed: rs.feed}
+	state.Vec = make([]int64, randLen)
+	copy(state.Vec, rs.vec[:])
+	return state
+}
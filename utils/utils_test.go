@@ -2,6 +2,7 @@ package utils
 
 import (
 	"github.com/stretchr/testify/assert"
+	"math/rand"
 	"testing"
 )
 
@@ -48,3 +49,57 @@ func TestProdInt(t *testing.T) {
 	assert.Equal(t, expected, actual)
 
 }
+
+func TestRandSourceState(t *testing.T) {
+	src := NewRandSource(42)
+	r := rand.New(src)
+	for i := 0; i < 10; i++ {
+		r.Float64()
+		r.Intn(7)
+	}
+
+	restoredSrc, err := NewRandSourceFromState(src.State())
+	assert.Nil(t, err)
+	restored := rand.New(restoredSrc)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, r.Int63(), restored.Int63())
+		assert.Equal(t, r.Uint64(), restored.Uint64())
+	}
+
+	// Same seed produces the same sequence as math/rand
+	assert.Equal(t, rand.New(rand.NewSource(7)).Int63(), rand.New(NewRandSource(7)).Int63())
+}
+
+func TestRandSourceMatchesMathRand(t *testing.T) {
+	for _, seed := range []int64{0, 1, 42, 1 << 40} {
+		expected := rand.NewSource(seed).(rand.Source64)
+		src := NewRandSource(seed)
+		for i := 0; i < 2000; i++ {
+			assert.Equal(t, expected.Uint64(), src.Uint64())
+		}
+	}
+}
+
+func TestRandSourceStateInvalid(t *testing.T) {
+	state := NewRandSource(42).State()
+	state.Vec = state.Vec[1:]
+	_, err := NewRandSourceFromState(state)
+	assert.NotNil(t, err)
+
+	state = NewRandSource(42).State()
+	state.Tap = state.Feed
+	_, err = NewRandSourceFromState(state)
+	assert.NotNil(t, err)
+}
+
+func BenchmarkRandSourceRestore(b *testing.B) {
+	src := NewRandSource(42)
+	for i := 0; i < 1000000; i++ {
+		src.Int63()
+	}
+	state := src.State()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewRandSourceFromState(state)
+	}
+}